@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"net/http"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	cauth "github.com/pip-services3-go/pip-services3-components-go/auth"
+	services "github.com/pip-services3-go/pip-services3-rpc-go/services"
+)
+
+// InvalidApiKeyErrorCode is returned for unknown or revoked API keys
+const InvalidApiKeyErrorCode = "INVALID_API_KEY"
+
+/*
+ApiKeyAuthManager is an interceptor that authenticates machine-to-machine callers by API keys
+passed in a header or a query parameter and puts the client into request context (see WithUser).
+Client roles and key scopes become user roles, so RoleAuthManager can be used after it.
+Scopes are also available in "scopes" user property.
+
+Keys are looked up in IApiKeyStore. By default keys are read from configuration into MemoryApiKeyStore.
+When a credential store is referenced and neither keys are configured nor a custom store is set,
+keys are looked up there with CredentialApiKeyStore. Set use_credential_store to always use it.
+A client can have several active keys at once to rotate them without downtime.
+
+Configuration parameters:
+
+  - header:                  header with API key (default: X-API-Key)
+  - query_param:             (optional) query parameter with API key, disabled by default
+  - use_credential_store:    look up keys in referenced credential store even when keys are configured (default: false)
+  - keys:                    API keys for MemoryApiKeyStore
+    - [client id]:
+      - keys:                comma-separated list of active API keys of the client
+      - roles:               (optional) comma-separated list of client roles
+      - scopes:              (optional) comma-separated list of scopes granted to the keys
+  - options:
+    - legacy_context:        also put the legacy "user" and "user_id" values into request context (default: false)
+
+References:
+
+- *:credential-store:*:*:1.0   (optional) ICredentialStore to look up API keys
+
+Example:
+
+	apiKeyAuth := auth.NewApiKeyAuthManager()
+	apiKeyAuth.Configure(cconf.NewConfigParamsFromTuples(
+		"keys.billing.keys", "key1,key2",
+		"keys.billing.roles", "admin",
+	))
+	roleAuth := &auth.RoleAuthManager{}
+
+	service.RegisterInterceptor("", apiKeyAuth.Authenticate())
+	service.RegisterRouteWithAuth("post", "/dummies", nil, roleAuth.Admin(), service.createDummy)
+*/
+type ApiKeyAuthManager struct {
+	// Header with API key.
+	Header string
+	// Query parameter with API key or empty string.
+	QueryParam string
+	// Puts the legacy "user" and "user_id" values into request context.
+	LegacyContext bool
+
+	store              IApiKeyStore
+	memoryStore        *MemoryApiKeyStore
+	useCredentialStore bool
+}
+
+// NewApiKeyAuthManager creates a new instance of the manager.
+// Returns: *ApiKeyAuthManager
+func NewApiKeyAuthManager() *ApiKeyAuthManager {
+	memoryStore := NewMemoryApiKeyStore()
+	return &ApiKeyAuthManager{
+		Header:      "X-API-Key",
+		store:       memoryStore,
+		memoryStore: memoryStore,
+	}
+}
+
+// Configure method are configures the manager by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *ApiKeyAuthManager) Configure(config *cconf.ConfigParams) {
+	c.Header = config.GetAsStringWithDefault("header", c.Header)
+	c.QueryParam = config.GetAsStringWithDefault("query_param", c.QueryParam)
+	c.useCredentialStore = config.GetAsBooleanWithDefault("use_credential_store", c.useCredentialStore)
+	c.LegacyContext = config.GetAsBooleanWithDefault("options.legacy_context", c.LegacyContext)
+	c.memoryStore.ReadKeys(config.GetSection("keys"))
+}
+
+// SetReferences method are sets references to the credential store to look up API keys.
+// The credential store is not used when keys are configured or a custom store is set,
+// unless use_credential_store is turned on.
+// Parameters:
+//   - references  crefer.IReferences  references to locate the component dependencies.
+func (c *ApiKeyAuthManager) SetReferences(references crefer.IReferences) {
+	if !c.useCredentialStore && (c.store != IApiKeyStore(c.memoryStore) || !c.memoryStore.isEmpty()) {
+		return
+	}
+	for _, ref := range references.GetOptional(crefer.NewDescriptor("*", "credential-store", "*", "*", "1.0")) {
+		if store, ok := ref.(cauth.ICredentialStore); ok {
+			c.store = NewCredentialApiKeyStore(store)
+			return
+		}
+	}
+}
+
+// SetStore method sets a custom store to look up API keys.
+// Parameters:
+//   - store  IApiKeyStore  the API key store.
+func (c *ApiKeyAuthManager) SetStore(store IApiKeyStore) {
+	c.store = store
+}
+
+// Authenticate method returns an interceptor that validates API keys and puts
+// the client into request context. Requests without keys are passed anonymously,
+// use Signed or other auth managers to require authentication.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *ApiKeyAuthManager) Authenticate() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.authenticate(res, req, next, false)
+	}
+}
+
+// Signed method returns an interceptor that requires a valid API key
+// and puts the client into request context.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *ApiKeyAuthManager) Signed() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.authenticate(res, req, next, true)
+	}
+}
+
+func (c *ApiKeyAuthManager) authenticate(res http.ResponseWriter, req *http.Request, next http.HandlerFunc, required bool) {
+	key := ""
+	if c.Header != "" {
+		key = req.Header.Get(c.Header)
+	}
+	if key == "" && c.QueryParam != "" {
+		key = req.URL.Query().Get(c.QueryParam)
+	}
+
+	if key == "" {
+		if required {
+			services.HttpResponseSender.SendError(res, req,
+				cerr.NewUnauthorizedError("", "NOT_SIGNED",
+					"User must be signed in to perform this operation").WithStatus(401))
+			return
+		}
+		next.ServeHTTP(res, req)
+		return
+	}
+
+	apiKey, err := c.store.Lookup("", key)
+	if err != nil {
+		services.HttpResponseSender.SendError(res, req, err)
+		return
+	}
+	if apiKey == nil {
+		services.HttpResponseSender.SendError(res, req,
+			cerr.NewUnauthorizedError("", InvalidApiKeyErrorCode, "API key is invalid or revoked").WithStatus(401))
+		return
+	}
+
+	roles := append(append([]string{}, apiKey.Roles...), apiKey.Scopes...)
+	scopes := make([]interface{}, len(apiKey.Scopes))
+	for i, scope := range apiKey.Scopes {
+		scopes[i] = scope
+	}
+	principal := NewPrincipal(apiKey.Id, roles, cdata.NewAnyValueMapFromTuples("scopes", scopes))
+	next.ServeHTTP(res, req.WithContext(withUser(req.Context(), principal, c.LegacyContext)))
+}
@@ -1,14 +1,113 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
 	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
 	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 	services "github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// InvalidCredentialsErrorCode is returned for wrong user names or passwords
+const InvalidCredentialsErrorCode = "INVALID_CREDENTIALS"
+
+/*
+BasicAuthManager checks that requests are signed and authenticates users
+by HTTP Basic and optionally Digest (RFC 7616, MD5, qop=auth) authentication.
+
+Users are looked up in IUserStore. By default users are read from configuration
+into MemoryUserStore. Basic authentication checks bcrypt password hashes,
+Digest authentication requires MD5 hashes of "username:realm:password" (see DigestHA1).
+On 401 responses the manager sends WWW-Authenticate challenges.
+
+The zero value without users only provides Anybody and Signed checks.
+
+Configuration parameters:
+
+  - realm:                   authentication realm (default: pip-services)
+  - digest:                  turns on Digest authentication (default: false)
+  - nonce_lifetime:          lifetime of Digest nonces in milliseconds (default: 300000), requests with
+                             nonce counts (nc) that do not increase are rejected as replayed
+  - users:                   users for MemoryUserStore
+    - [username]:
+      - id:                  (optional) unique user id (default: username)
+      - password_hash:       bcrypt hash of the password
+      - digest_ha1:          (optional) hex MD5 hash of "username:realm:password"
+      - roles:               (optional) comma-separated list of user roles
+  - options:
+    - legacy_context:        also put the legacy "user" and "user_id" values into request context (default: false)
+
+Example:
+
+	basicAuth := auth.NewBasicAuthManager()
+	basicAuth.Configure(cconf.NewConfigParamsFromTuples(
+		"users.admin.password_hash", "$2a$10$...",
+		"users.admin.roles", "admin",
+	))
+
+	service.RegisterInterceptor("", basicAuth.Authenticate())
+	service.RegisterRouteWithAuth("get", "/dummies", nil, basicAuth.Signed(), service.getDummies)
+*/
 type BasicAuthManager struct {
+	// Authentication realm.
+	Realm string
+	// Turns on Digest authentication.
+	Digest bool
+	// Lifetime of Digest nonces.
+	NonceLifetime time.Duration
+	// Puts the legacy "user" and "user_id" values into request context.
+	LegacyContext bool
+
+	store       IUserStore
+	memoryStore *MemoryUserStore
+	secretOnce  sync.Once
+	nonceSecret []byte
+	nonceCounts digestNonceCounter
+}
+
+// NewBasicAuthManager creates a new instance of the manager with empty MemoryUserStore.
+// Returns: *BasicAuthManager
+func NewBasicAuthManager() *BasicAuthManager {
+	memoryStore := NewMemoryUserStore()
+	return &BasicAuthManager{
+		Realm:         "pip-services",
+		NonceLifetime: 5 * time.Minute,
+		store:         memoryStore,
+		memoryStore:   memoryStore,
+	}
+}
+
+// Configure method are configures the manager by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *BasicAuthManager) Configure(config *cconf.ConfigParams) {
+	c.Realm = config.GetAsStringWithDefault("realm", c.realm())
+	c.Digest = config.GetAsBooleanWithDefault("digest", c.Digest)
+	c.NonceLifetime = time.Duration(config.GetAsLongWithDefault("nonce_lifetime",
+		c.nonceLifetime().Milliseconds())) * time.Millisecond
+	c.LegacyContext = config.GetAsBooleanWithDefault("options.legacy_context", c.LegacyContext)
+	if c.memoryStore == nil {
+		c.memoryStore = NewMemoryUserStore()
+		if c.store == nil {
+			c.store = c.memoryStore
+		}
+	}
+	c.memoryStore.ReadUsers(config.GetSection("users"))
+}
+
+// SetStore method sets a custom store to look up users.
+// Parameters:
+//   - store  IUserStore  the user store.
+func (c *BasicAuthManager) SetStore(store IUserStore) {
+	c.store = store
 }
 
 func (c *BasicAuthManager) Anybody() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
@@ -19,8 +118,11 @@ func (c *BasicAuthManager) Anybody() func(res http.ResponseWriter, req *http.Req
 
 func (c *BasicAuthManager) Signed() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
-		_, ok := req.Context().Value("user").(cdata.AnyValueMap)
+		_, ok := UserFromRequest(req)
 		if !ok {
+			if c.store != nil {
+				c.challenge(res, false)
+			}
 			services.HttpResponseSender.SendError(
 				res, req,
 				cerr.NewUnauthorizedError("",
@@ -33,3 +135,153 @@ func (c *BasicAuthManager) Signed() func(res http.ResponseWriter, req *http.Requ
 		}
 	}
 }
+
+// Authenticate method returns an interceptor that authenticates users by Basic or Digest
+// Authorization header and puts them into request context. Requests without credentials
+// or with other authorization schemes are passed anonymously, use Signed or other auth managers
+// to require authentication.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *BasicAuthManager) Authenticate() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		header := req.Header.Get("Authorization")
+		scheme, credentials := header, ""
+		if index := strings.Index(header, " "); index >= 0 {
+			scheme, credentials = header[:index], strings.TrimSpace(header[index+1:])
+		}
+
+		var user *UserCredential
+		var stale bool
+		var err error
+		switch {
+		case strings.EqualFold(scheme, "Basic") && c.store != nil:
+			user, err = c.authenticateBasic(credentials)
+		case strings.EqualFold(scheme, "Digest") && c.Digest && c.store != nil:
+			user, stale, err = c.authenticateDigest(req, credentials)
+		default:
+			next.ServeHTTP(res, req)
+			return
+		}
+
+		if err != nil {
+			services.HttpResponseSender.SendError(res, req, err)
+			return
+		}
+		if user == nil {
+			c.challenge(res, stale)
+			services.HttpResponseSender.SendError(res, req,
+				cerr.NewUnauthorizedError("", InvalidCredentialsErrorCode,
+					"User name or password is invalid").WithStatus(401))
+			return
+		}
+
+		principal := NewPrincipal(user.Id, user.Roles, cdata.NewAnyValueMapFromTuples("login", user.Username))
+		next.ServeHTTP(res, req.WithContext(withUser(req.Context(), principal, c.LegacyContext)))
+	}
+}
+
+func (c *BasicAuthManager) authenticateBasic(credentials string) (*UserCredential, error) {
+	data, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return nil, nil
+	}
+	parts := strings.SplitN(string(data), ":", 2)
+	if len(parts) != 2 {
+		return nil, nil
+	}
+
+	user, err := c.store.Lookup("", parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.PasswordHash == "" {
+		// Compare with a dummy hash to not reveal existing users by response time
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(parts[1]))
+		return nil, nil
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(parts[1])) != nil {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (c *BasicAuthManager) authenticateDigest(req *http.Request, credentials string) (*UserCredential, bool, error) {
+	params := parseDigestParams(credentials)
+	if params["realm"] != c.realm() || params["qop"] != "auth" ||
+		(params["algorithm"] != "" && !strings.EqualFold(params["algorithm"], "MD5")) ||
+		params["uri"] != req.URL.RequestURI() {
+		return nil, false, nil
+	}
+	now := time.Now()
+	issued, valid, stale := checkDigestNonce(c.secret(), params["nonce"], c.nonceLifetime(), now)
+	if !valid || stale {
+		return nil, stale, nil
+	}
+
+	user, err := c.store.Lookup("", params["username"])
+	if err != nil {
+		return nil, false, err
+	}
+	if user == nil || user.DigestHA1 == "" {
+		return nil, false, nil
+	}
+
+	ha2 := md5Hex(req.Method + ":" + params["uri"])
+	expected := md5Hex(user.DigestHA1 + ":" + params["nonce"] + ":" + params["nc"] + ":" +
+		params["cnonce"] + ":auth:" + ha2)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(params["response"]))) != 1 {
+		return nil, false, nil
+	}
+
+	// Reject replayed requests, the nonce count must increase with every request
+	valid, stale = c.nonceCounts.use(params["nonce"], issued, params["nc"], c.nonceLifetime(), now)
+	if !valid || stale {
+		return nil, stale, nil
+	}
+	return user, false, nil
+}
+
+// Sends WWW-Authenticate challenges for supported schemes
+func (c *BasicAuthManager) challenge(res http.ResponseWriter, stale bool) {
+	res.Header().Add("WWW-Authenticate", "Basic realm=\""+c.realm()+"\", charset=\"UTF-8\"")
+	if c.Digest {
+		challenge := "Digest realm=\"" + c.realm() + "\", qop=\"auth\", algorithm=MD5, nonce=\"" +
+			newDigestNonce(c.secret(), time.Now()) + "\""
+		if stale {
+			challenge += ", stale=true"
+		}
+		res.Header().Add("WWW-Authenticate", challenge)
+	}
+}
+
+func (c *BasicAuthManager) realm() string {
+	if c.Realm == "" {
+		return "pip-services"
+	}
+	return c.Realm
+}
+
+func (c *BasicAuthManager) nonceLifetime() time.Duration {
+	if c.NonceLifetime <= 0 {
+		return 5 * time.Minute
+	}
+	return c.NonceLifetime
+}
+
+// Random secret to sign Digest nonces generated once per manager
+func (c *BasicAuthManager) secret() []byte {
+	c.secretOnce.Do(func() {
+		c.nonceSecret = make([]byte, 32)
+		rand.Read(c.nonceSecret)
+	})
+	return c.nonceSecret
+}
+
+var dummyHashOnce sync.Once
+var dummyHash []byte
+
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
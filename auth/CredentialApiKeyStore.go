@@ -0,0 +1,53 @@
+package auth
+
+import (
+	cauth "github.com/pip-services3-go/pip-services3-components-go/auth"
+)
+
+/*
+CredentialApiKeyStore looks up API keys in a credential store, where
+credentials are stored under API keys as their keys.
+
+Credential parameters:
+
+  - access_id:               id of the client (username is used when it is not set)
+  - roles:                   (optional) comma-separated list of client roles
+  - scopes:                  (optional) comma-separated list of scopes granted to the key
+
+To rotate keys store the new key with the same access_id and remove the old one after clients switch.
+*/
+type CredentialApiKeyStore struct {
+	store cauth.ICredentialStore
+}
+
+// NewCredentialApiKeyStore creates a new store on top of the credential store.
+// Parameters:
+//   - store  cauth.ICredentialStore  the credential store.
+//
+// Returns: *CredentialApiKeyStore
+func NewCredentialApiKeyStore(store cauth.ICredentialStore) *CredentialApiKeyStore {
+	return &CredentialApiKeyStore{store: store}
+}
+
+// Lookup method finds the client by API key.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - key            string  the API key.
+//
+// Returns: *ApiKey, error
+// the client or nil when the key is unknown.
+func (c *CredentialApiKeyStore) Lookup(correlationId string, key string) (*ApiKey, error) {
+	credential, err := c.store.Lookup(correlationId, key)
+	if err != nil || credential == nil {
+		return nil, err
+	}
+	id := credential.AccessId()
+	if id == "" {
+		id = credential.Username()
+	}
+	return &ApiKey{
+		Id:     id,
+		Roles:  splitList(credential.GetAsString("roles")),
+		Scopes: splitList(credential.GetAsString("scopes")),
+	}, nil
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum number of Digest nonces with tracked nonce counts
+const digestMaxNonces = 10000
+
+// DigestHA1 calculates hex MD5 hash of "username:realm:password" to keep in user stores
+// for Digest authentication instead of plain passwords.
+// Parameters:
+//   - username  string  the user login.
+//   - realm     string  the authentication realm.
+//   - password  string  the user password.
+//
+// Returns: string
+func DigestHA1(username string, realm string, password string) string {
+	return md5Hex(username + ":" + realm + ":" + password)
+}
+
+func md5Hex(value string) string {
+	hash := md5.Sum([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+// Creates a stateless nonce with the issue time and random bytes signed by the secret.
+// Random bytes keep nonces unique for clients challenged within the same second.
+func newDigestNonce(secret []byte, now time.Time) string {
+	data := make([]byte, 8+16, 8+16+16)
+	binary.BigEndian.PutUint64(data, uint64(now.Unix()))
+	rand.Read(data[8:])
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	data = append(data, mac.Sum(nil)[:16]...)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Checks the nonce signature and returns false in valid if it is forged
+// or true in stale if it is expired
+func checkDigestNonce(secret []byte, nonce string, lifetime time.Duration,
+	now time.Time) (issued time.Time, valid bool, stale bool) {
+	data, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(data) != 8+16+16 {
+		return issued, false, false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data[:8+16])
+	if !hmac.Equal(mac.Sum(nil)[:16], data[8+16:]) {
+		return issued, false, false
+	}
+	issued = time.Unix(int64(binary.BigEndian.Uint64(data[:8])), 0)
+	if now.Sub(issued) > lifetime {
+		return issued, true, true
+	}
+	return issued, true, false
+}
+
+type digestNonceCount struct {
+	count  uint64
+	issued time.Time
+}
+
+// Keeps the last nonce count (nc) of every used Digest nonce to reject replayed requests.
+// The number of tracked nonces is bounded: when it is exceeded the oldest nonces
+// are forgotten and become stale, so clients have to request new ones.
+type digestNonceCounter struct {
+	lock          sync.Mutex
+	counts        map[string]*digestNonceCount
+	evictedBefore time.Time
+}
+
+// Records the nonce count and returns false in valid if it does not increase
+// or true in stale if the nonce is no longer tracked
+func (c *digestNonceCounter) use(nonce string, issued time.Time, nc string, lifetime time.Duration,
+	now time.Time) (valid bool, stale bool) {
+	count, err := strconv.ParseUint(nc, 16, 64)
+	if err != nil || count == 0 {
+		return false, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if record, ok := c.counts[nonce]; ok {
+		if count <= record.count {
+			return false, false
+		}
+		record.count = count
+		return true, false
+	}
+	if !issued.After(c.evictedBefore) {
+		return false, true
+	}
+
+	if c.counts == nil {
+		c.counts = make(map[string]*digestNonceCount)
+	}
+	if len(c.counts) >= digestMaxNonces {
+		c.evict(lifetime, now)
+	}
+	c.counts[nonce] = &digestNonceCount{count: count, issued: issued}
+	return true, false
+}
+
+// Removes expired nonces or the oldest nonce when all of them are alive
+func (c *digestNonceCounter) evict(lifetime time.Duration, now time.Time) {
+	var oldestNonce string
+	var oldest *digestNonceCount
+	for nonce, record := range c.counts {
+		if now.Sub(record.issued) > lifetime {
+			delete(c.counts, nonce)
+		} else if oldest == nil || record.issued.Before(oldest.issued) {
+			oldestNonce, oldest = nonce, record
+		}
+	}
+	if len(c.counts) >= digestMaxNonces && oldest != nil {
+		delete(c.counts, oldestNonce)
+		if oldest.issued.After(c.evictedBefore) {
+			c.evictedBefore = oldest.issued
+		}
+	}
+}
+
+// Parses parameters of Digest authorization header, i.e. username="admin", nc=00000001
+func parseDigestParams(value string) map[string]string {
+	params := make(map[string]string)
+	for len(value) > 0 {
+		value = strings.TrimLeft(value, " ,")
+		eq := strings.Index(value, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(value[:eq]))
+		value = strings.TrimLeft(value[eq+1:], " ")
+
+		var param string
+		if strings.HasPrefix(value, "\"") {
+			end := 1
+			var builder strings.Builder
+			for ; end < len(value) && value[end] != '"'; end++ {
+				if value[end] == '\\' && end+1 < len(value) {
+					end++
+				}
+				builder.WriteByte(value[end])
+			}
+			param = builder.String()
+			if end < len(value) {
+				end++
+			}
+			value = value[end:]
+		} else {
+			end := strings.Index(value, ",")
+			if end < 0 {
+				end = len(value)
+			}
+			param = strings.TrimSpace(value[:end])
+			value = value[end:]
+		}
+		params[key] = param
+	}
+	return params
+}
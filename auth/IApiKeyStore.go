@@ -0,0 +1,19 @@
+package auth
+
+// ApiKey describes the client authenticated by an API key
+type ApiKey struct {
+	// Id of the client, used as the user id.
+	Id string
+	// Roles of the client.
+	Roles []string
+	// Scopes granted to the key.
+	Scopes []string
+}
+
+// IApiKeyStore is a store of API keys used by ApiKeyAuthManager.
+// A client may have several active keys at once to support key rotation.
+type IApiKeyStore interface {
+	// Lookup finds the client by API key.
+	// Returns nil without error when the key is unknown or revoked.
+	Lookup(correlationId string, key string) (*ApiKey, error)
+}
@@ -0,0 +1,22 @@
+package auth
+
+// UserCredential describes a user authenticated with a password by BasicAuthManager
+type UserCredential struct {
+	// Unique user id.
+	Id string
+	// User login.
+	Username string
+	// Bcrypt hash of the password for Basic authentication.
+	PasswordHash string
+	// Hex MD5 hash of "username:realm:password" for Digest authentication.
+	DigestHA1 string
+	// User roles.
+	Roles []string
+}
+
+// IUserStore is a store of user credentials used by BasicAuthManager
+type IUserStore interface {
+	// Lookup finds the user by login.
+	// Returns nil without error when the user is unknown.
+	Lookup(correlationId string, username string) (*UserCredential, error)
+}
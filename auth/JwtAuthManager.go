@@ -0,0 +1,357 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cconv "github.com/pip-services3-go/pip-services3-commons-go/convert"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	services "github.com/pip-services3-go/pip-services3-rpc-go/services"
+)
+
+const (
+	// InvalidTokenErrorCode is returned for malformed tokens or tokens with invalid signature or claims
+	InvalidTokenErrorCode = "INVALID_TOKEN"
+	// TokenExpiredErrorCode is returned for expired tokens
+	TokenExpiredErrorCode = "TOKEN_EXPIRED"
+)
+
+/*
+JwtAuthManager is an interceptor that authenticates users by JWT bearer tokens
+in Authorization header and puts the user with token claims into request context (see WithUser),
+so BasicAuthManager, RoleAuthManager and OwnerAuthManager can be used after it.
+
+Supported signature algorithms are HS256 with a shared secret, RS256 and ES256
+with a configured public key or keys from JSON Web Key Set (JWKS).
+
+Configuration parameters:
+
+  - secret:                  shared secret for HS256 tokens
+  - public_key:              PEM encoded RSA or EC public key or certificate for RS256 and ES256 tokens
+  - public_key_file:         path to the file with PEM encoded public key or certificate
+  - jwks:
+    - uri:                   path to local file or http(s) URL of JWKS
+    - refresh_interval:      interval to reload JWKS in milliseconds (default: 3600000)
+  - algorithms:              comma-separated list of accepted algorithms (default: HS256,RS256,ES256)
+  - issuer:                  (optional) required "iss" claim
+  - audience:                (optional) required "aud" claim
+  - clock_skew:              allowed clock skew to check "exp", "nbf" and "iat" claims in milliseconds (default: 60000)
+  - claims:
+    - user_id:               claim with the user id (default: sub)
+    - roles:                 claim with user roles as array or space-separated string (default: roles)
+  - options:
+    - legacy_context:        also put the legacy "user" and "user_id" values into request context (default: false)
+
+Example:
+
+	jwtAuth := auth.NewJwtAuthManager()
+	jwtAuth.Configure(cconf.NewConfigParamsFromTuples(
+		"jwks.uri", "https://example.com/.well-known/jwks.json",
+		"issuer", "https://example.com",
+		"audience", "myservice",
+	))
+	roleAuth := &auth.RoleAuthManager{}
+
+	service.RegisterInterceptor("", jwtAuth.Authenticate())
+	service.RegisterRouteWithAuth("post", "/dummies", nil, roleAuth.Admin(), service.createDummy)
+*/
+type JwtAuthManager struct {
+	// Shared secret for HS256 tokens.
+	Secret []byte
+	// Public key for RS256 and ES256 tokens.
+	PublicKey crypto.PublicKey
+	// Key set for RS256 and ES256 tokens.
+	KeySet *JwtKeySet
+	// Accepted signature algorithms.
+	Algorithms []string
+	// Required issuer or empty string.
+	Issuer string
+	// Required audience or empty string.
+	Audience string
+	// Allowed clock skew.
+	ClockSkew time.Duration
+	// Claim with the user id.
+	UserIdClaim string
+	// Claim with user roles.
+	RolesClaim string
+	// Puts the legacy "user" and "user_id" values into request context.
+	LegacyContext bool
+
+	configErr error
+}
+
+// NewJwtAuthManager creates a new instance of the manager.
+// Returns: *JwtAuthManager
+func NewJwtAuthManager() *JwtAuthManager {
+	return &JwtAuthManager{
+		Algorithms:  []string{"HS256", "RS256", "ES256"},
+		ClockSkew:   time.Minute,
+		UserIdClaim: "sub",
+		RolesClaim:  "roles",
+	}
+}
+
+// Configure method are configures the manager by passing configuration parameters.
+// When the public key cannot be loaded all tokens are rejected with the configuration error.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *JwtAuthManager) Configure(config *cconf.ConfigParams) {
+	c.configErr = nil
+	if secret := config.GetAsString("secret"); secret != "" {
+		c.Secret = []byte(secret)
+	}
+
+	publicKey := config.GetAsString("public_key")
+	if file := config.GetAsString("public_key_file"); file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			c.configErr = cerr.NewFileError("", "CANNOT_READ_KEY", "Failed to read public key from "+file).WithCause(err)
+		}
+		publicKey = string(data)
+	}
+	if publicKey != "" {
+		key, err := ParsePublicKey([]byte(publicKey))
+		if err != nil {
+			c.configErr = cerr.NewConfigError("", "INVALID_KEY", "Public key is invalid").WithCause(err)
+		}
+		c.PublicKey = key
+	}
+
+	if uri := config.GetAsString("jwks.uri"); uri != "" {
+		refresh := config.GetAsLongWithDefault("jwks.refresh_interval", 3600000)
+		c.KeySet = NewJwtKeySet(uri, time.Duration(refresh)*time.Millisecond)
+	}
+
+	if algorithms := config.GetAsString("algorithms"); algorithms != "" {
+		c.Algorithms = make([]string, 0)
+		for _, algorithm := range strings.Split(algorithms, ",") {
+			c.Algorithms = append(c.Algorithms, strings.ToUpper(strings.TrimSpace(algorithm)))
+		}
+	}
+	c.Issuer = config.GetAsStringWithDefault("issuer", c.Issuer)
+	c.Audience = config.GetAsStringWithDefault("audience", c.Audience)
+	c.ClockSkew = time.Duration(config.GetAsLongWithDefault("clock_skew", c.ClockSkew.Milliseconds())) * time.Millisecond
+	c.UserIdClaim = config.GetAsStringWithDefault("claims.user_id", c.UserIdClaim)
+	c.RolesClaim = config.GetAsStringWithDefault("claims.roles", c.RolesClaim)
+	c.LegacyContext = config.GetAsBooleanWithDefault("options.legacy_context", c.LegacyContext)
+}
+
+// Authenticate method returns an interceptor that validates bearer tokens and puts
+// the user into request context. Requests without tokens or with other authorization
+// schemes are passed anonymously, use Signed or other auth managers to require authentication.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *JwtAuthManager) Authenticate() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.authenticate(res, req, next, false)
+	}
+}
+
+// Signed method returns an interceptor that requires a valid bearer token
+// and puts the user into request context.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *JwtAuthManager) Signed() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.authenticate(res, req, next, true)
+	}
+}
+
+func (c *JwtAuthManager) authenticate(res http.ResponseWriter, req *http.Request, next http.HandlerFunc, required bool) {
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		if required {
+			res.Header().Set("WWW-Authenticate", "Bearer")
+			services.HttpResponseSender.SendError(res, req,
+				cerr.NewUnauthorizedError("", "NOT_SIGNED",
+					"User must be signed in to perform this operation").WithStatus(401))
+			return
+		}
+		next.ServeHTTP(res, req)
+		return
+	}
+
+	token := ""
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		token = strings.TrimSpace(header[7:])
+	} else if !required {
+		// Leave other authorization schemes to other auth managers
+		next.ServeHTTP(res, req)
+		return
+	}
+	claims, err := c.ValidateToken("", token)
+	if err != nil {
+		if appErr, ok := err.(*cerr.ApplicationError); ok && appErr.Status == 401 {
+			// Error messages may contain token values, so only fixed descriptions are sent
+			description := "The access token is invalid"
+			if appErr.Code == TokenExpiredErrorCode {
+				description = "The access token expired"
+			}
+			res.Header().Set("WWW-Authenticate", "Bearer error=\"invalid_token\", error_description=\""+description+"\"")
+		}
+		services.HttpResponseSender.SendError(res, req, err)
+		return
+	}
+
+	next.ServeHTTP(res, req.WithContext(withUser(req.Context(), c.claimsToUser(claims), c.LegacyContext)))
+}
+
+// Converts claims into the user with id and roles
+func (c *JwtAuthManager) claimsToUser(claims map[string]interface{}) *Principal {
+	roles := make([]string, 0)
+	switch value := claims[c.RolesClaim].(type) {
+	case string:
+		roles = strings.Fields(value)
+	case []interface{}:
+		for _, role := range value {
+			if r, ok := role.(string); ok {
+				roles = append(roles, r)
+			}
+		}
+	}
+	id := cconv.StringConverter.ToString(claims[c.UserIdClaim])
+	return NewPrincipal(id, roles, cdata.NewAnyValueMap(claims))
+}
+
+// ValidateToken method validates signature and claims of the token.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - token          string  the token to validate.
+//
+// Returns: map[string]interface{}, error
+// token claims or 401 error with INVALID_TOKEN or TOKEN_EXPIRED code.
+func (c *JwtAuthManager) ValidateToken(correlationId string, token string) (map[string]interface{}, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+	invalid := func(message string) *cerr.ApplicationError {
+		return cerr.NewUnauthorizedError(correlationId, InvalidTokenErrorCode, message).WithStatus(401)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("Token is malformed")
+	}
+	headerData, headerErr := decodeBase64Url(parts[0])
+	claimsData, claimsErr := decodeBase64Url(parts[1])
+	signature, signatureErr := decodeBase64Url(parts[2])
+	if headerErr != nil || claimsErr != nil || signatureErr != nil {
+		return nil, invalid("Token is malformed")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	var claims map[string]interface{}
+	if json.Unmarshal(headerData, &header) != nil || json.Unmarshal(claimsData, &claims) != nil {
+		return nil, invalid("Token is malformed")
+	}
+	if !c.isAlgorithmAllowed(header.Alg) {
+		return nil, invalid("Token algorithm " + header.Alg + " is not allowed")
+	}
+	if !c.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature) {
+		return nil, invalid("Token signature is invalid")
+	}
+	if err := c.validateClaims(correlationId, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (c *JwtAuthManager) isAlgorithmAllowed(algorithm string) bool {
+	for _, allowed := range c.Algorithms {
+		if allowed == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *JwtAuthManager) verifySignature(algorithm string, kid string, signed string, signature []byte) bool {
+	hash := sha256.Sum256([]byte(signed))
+
+	if algorithm == "HS256" {
+		if len(c.Secret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, c.Secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+
+	key := c.PublicKey
+	if c.KeySet != nil && (key == nil || kid != "") {
+		var err error
+		if key, err = c.KeySet.GetKey(kid); err != nil {
+			return false
+		}
+	}
+
+	switch algorithm {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hash[:], signature) == nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve != elliptic.P256() || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(ecKey, hash[:], r, s)
+	}
+	return false
+}
+
+func (c *JwtAuthManager) validateClaims(correlationId string, claims map[string]interface{}) *cerr.ApplicationError {
+	now := time.Now()
+	skew := c.ClockSkew
+
+	if exp, ok := claims["exp"].(float64); ok {
+		if now.After(time.Unix(int64(exp), 0).Add(skew)) {
+			return cerr.NewUnauthorizedError(correlationId, TokenExpiredErrorCode, "Token is expired").WithStatus(401)
+		}
+	} else if _, found := claims["exp"]; found {
+		return cerr.NewUnauthorizedError(correlationId, InvalidTokenErrorCode, "Token expiration is invalid").WithStatus(401)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(skew).Before(time.Unix(int64(nbf), 0)) {
+		return cerr.NewUnauthorizedError(correlationId, InvalidTokenErrorCode, "Token is not valid yet").WithStatus(401)
+	}
+	if iat, ok := claims["iat"].(float64); ok && now.Add(skew).Before(time.Unix(int64(iat), 0)) {
+		return cerr.NewUnauthorizedError(correlationId, InvalidTokenErrorCode, "Token is issued in the future").WithStatus(401)
+	}
+
+	if c.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != c.Issuer {
+			return cerr.NewUnauthorizedError(correlationId, InvalidTokenErrorCode, "Token issuer is invalid").WithStatus(401)
+		}
+	}
+	if c.Audience != "" {
+		matched := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			matched = aud == c.Audience
+		case []interface{}:
+			for _, value := range aud {
+				if value == c.Audience {
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return cerr.NewUnauthorizedError(correlationId, InvalidTokenErrorCode, "Token audience is invalid").WithStatus(401)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimum time between reloads of JWKS caused by tokens with unknown key ids
+const jwksMinReloadInterval = 10 * time.Second
+
+// JSON Web Key as defined by RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+/*
+JwtKeySet keeps public keys used to verify JWT signatures loaded
+from JSON Web Key Set (JWKS) located in a local file or at http(s) URL.
+
+Keys are reloaded when the refresh interval passes or when a token is signed
+with an unknown key id, i.e. after key rotation by the identity provider.
+Only one goroutine reloads the keys, others keep using the cached keys meanwhile.
+When the key set fails to load, the error is returned without reloading for 10 seconds.
+*/
+type JwtKeySet struct {
+	// Path to local file or http(s) URL of the key set.
+	Uri string
+	// Interval to reload the key set, 0 to load it once.
+	RefreshInterval time.Duration
+
+	lock     sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadTime time.Time
+	loadErr  error
+	loading  chan struct{}
+	client   *http.Client
+}
+
+// NewJwtKeySet creates a new key set loaded from the given location.
+// Parameters:
+//   - uri              string         path to local file or http(s) URL of the key set.
+//   - refreshInterval  time.Duration  interval to reload the key set, 0 to load it once.
+//
+// Returns: *JwtKeySet
+func NewJwtKeySet(uri string, refreshInterval time.Duration) *JwtKeySet {
+	return &JwtKeySet{
+		Uri:             uri,
+		RefreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetKey gets the key by its id. When the key set contains a single key
+// it is returned for tokens without key id.
+// Parameters:
+//   - kid  string  the key id from the token header.
+//
+// Returns: crypto.PublicKey, error
+func (c *JwtKeySet) GetKey(kid string) (crypto.PublicKey, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	expired := c.keys == nil || (c.RefreshInterval > 0 && time.Since(c.loadTime) > c.RefreshInterval)
+	if !expired && c.findKey(kid) == nil && time.Since(c.loadTime) > jwksMinReloadInterval {
+		expired = true
+	}
+	if c.keys == nil && c.loadErr != nil && time.Since(c.loadTime) < jwksMinReloadInterval {
+		// Do not reload unavailable key set on every request
+		expired = false
+	}
+	if expired {
+		if c.loading == nil {
+			c.refresh()
+		} else if c.keys == nil {
+			// Wait for the first load started by another goroutine
+			loading := c.loading
+			c.lock.Unlock()
+			<-loading
+			c.lock.Lock()
+		}
+	}
+
+	if c.keys == nil {
+		return nil, c.loadErr
+	}
+	key := c.findKey(kid)
+	if key == nil {
+		return nil, fmt.Errorf("key %s is not found in JWKS", kid)
+	}
+	return key, nil
+}
+
+// Reloads the keys without holding the lock, it must be called with the lock held
+func (c *JwtKeySet) refresh() {
+	loading := make(chan struct{})
+	c.loading = loading
+	c.lock.Unlock()
+
+	keys, err := c.load()
+
+	c.lock.Lock()
+	if err == nil {
+		c.keys = keys
+	}
+	c.loadErr = err
+	c.loadTime = time.Now()
+	c.loading = nil
+	close(loading)
+}
+
+func (c *JwtKeySet) findKey(kid string) crypto.PublicKey {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key
+		}
+	}
+	return c.keys[kid]
+}
+
+func (c *JwtKeySet) load() (map[string]crypto.PublicKey, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(c.Uri, "http://") || strings.HasPrefix(c.Uri, "https://") {
+		var resp *http.Response
+		resp, err = c.client.Get(c.Uri)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to load JWKS from %s: status %d", c.Uri, resp.StatusCode)
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+	} else {
+		data, err = ioutil.ReadFile(c.Uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseJwks(data)
+}
+
+// ParseJwks parses public keys from JSON Web Key Set. Only RSA and EC P-256 signature keys are supported,
+// other keys are skipped.
+// Parameters:
+//   - data  []byte  JSON of the key set.
+//
+// Returns: map[string]crypto.PublicKey, error
+// keys by their ids.
+func ParseJwks(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			n, nErr := decodeBase64Url(jwk.N)
+			e, eErr := decodeBase64Url(jwk.E)
+			if nErr != nil || eErr != nil || len(e) > 4 {
+				return nil, fmt.Errorf("RSA key %s is invalid", jwk.Kid)
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			if jwk.Crv != "P-256" {
+				continue
+			}
+			x, xErr := decodeBase64Url(jwk.X)
+			y, yErr := decodeBase64Url(jwk.Y)
+			if xErr != nil || yErr != nil {
+				return nil, fmt.Errorf("EC key %s is invalid", jwk.Kid)
+			}
+			key := &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+			if !key.Curve.IsOnCurve(key.X, key.Y) {
+				return nil, fmt.Errorf("EC key %s is not on curve", jwk.Kid)
+			}
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// ParsePublicKey parses RSA or EC public key from PEM encoded public key or certificate.
+// Parameters:
+//   - data  []byte  PEM encoded key.
+//
+// Returns: crypto.PublicKey, error
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
+
+func decodeBase64Url(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
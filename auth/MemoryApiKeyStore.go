@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+)
+
+/*
+MemoryApiKeyStore keeps API keys in memory. Keys are read from configuration,
+where each section describes a client.
+
+Configuration parameters:
+
+  - [client id]:
+    - keys:                  comma-separated list of active API keys of the client
+    - roles:                 (optional) comma-separated list of client roles
+    - scopes:                (optional) comma-separated list of scopes granted to the keys
+
+Example:
+
+	store := auth.NewMemoryApiKeyStore()
+	store.Configure(cconf.NewConfigParamsFromTuples(
+		"billing.keys", "key1,key2",
+		"billing.roles", "admin",
+	))
+*/
+type MemoryApiKeyStore struct {
+	lock  sync.RWMutex
+	items map[string]*ApiKey
+}
+
+// NewMemoryApiKeyStore creates a new empty store.
+// Returns: *MemoryApiKeyStore
+func NewMemoryApiKeyStore() *MemoryApiKeyStore {
+	return &MemoryApiKeyStore{
+		items: make(map[string]*ApiKey),
+	}
+}
+
+// Configure method are configures the store by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *MemoryApiKeyStore) Configure(config *cconf.ConfigParams) {
+	c.ReadKeys(config)
+}
+
+// ReadKeys method are reads API keys from configuration parameters replacing existing keys.
+// Each section represents a client.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be read.
+func (c *MemoryApiKeyStore) ReadKeys(config *cconf.ConfigParams) {
+	items := make(map[string]*ApiKey)
+	for _, id := range config.GetSectionNames() {
+		section := config.GetSection(id)
+		apiKey := &ApiKey{
+			Id:     id,
+			Roles:  splitList(section.GetAsString("roles")),
+			Scopes: splitList(section.GetAsString("scopes")),
+		}
+		for _, key := range splitList(section.GetAsString("keys")) {
+			items[key] = apiKey
+		}
+	}
+
+	c.lock.Lock()
+	c.items = items
+	c.lock.Unlock()
+}
+
+// AddKey method adds an active API key, i.e. a new key during rotation.
+// Parameters:
+//   - key     string   the API key.
+//   - apiKey  *ApiKey  the client authenticated by the key.
+func (c *MemoryApiKeyStore) AddKey(key string, apiKey *ApiKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.items[key] = apiKey
+}
+
+// RemoveKey method revokes the API key, i.e. an old key after rotation.
+// Parameters:
+//   - key  string  the API key.
+func (c *MemoryApiKeyStore) RemoveKey(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.items, key)
+}
+
+// Lookup method finds the client by API key.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - key            string  the API key.
+//
+// Returns: *ApiKey, error
+// the client or nil when the key is unknown.
+func (c *MemoryApiKeyStore) Lookup(correlationId string, key string) (*ApiKey, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.items[key], nil
+}
+
+// Checks if the store has no keys
+func (c *MemoryApiKeyStore) isEmpty() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.items) == 0
+}
+
+func splitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
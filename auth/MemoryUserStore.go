@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"sync"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+)
+
+/*
+MemoryUserStore keeps user credentials in memory. Users are read from configuration,
+where each section describes a user by login.
+
+Configuration parameters:
+
+  - [username]:
+    - id:                    (optional) unique user id (default: username)
+    - password_hash:         bcrypt hash of the password for Basic authentication
+    - digest_ha1:            (optional) hex MD5 hash of "username:realm:password" for Digest authentication
+    - roles:                 (optional) comma-separated list of user roles
+
+Example:
+
+	store := auth.NewMemoryUserStore()
+	store.Configure(cconf.NewConfigParamsFromTuples(
+		"admin.password_hash", "$2a$10$...",
+		"admin.roles", "admin",
+	))
+*/
+type MemoryUserStore struct {
+	lock  sync.RWMutex
+	items map[string]*UserCredential
+}
+
+// NewMemoryUserStore creates a new empty store.
+// Returns: *MemoryUserStore
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		items: make(map[string]*UserCredential),
+	}
+}
+
+// Configure method are configures the store by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *MemoryUserStore) Configure(config *cconf.ConfigParams) {
+	c.ReadUsers(config)
+}
+
+// ReadUsers method are reads users from configuration parameters replacing existing users.
+// Each section represents a user.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be read.
+func (c *MemoryUserStore) ReadUsers(config *cconf.ConfigParams) {
+	items := make(map[string]*UserCredential)
+	for _, username := range config.GetSectionNames() {
+		section := config.GetSection(username)
+		items[username] = &UserCredential{
+			Id:           section.GetAsStringWithDefault("id", username),
+			Username:     username,
+			PasswordHash: section.GetAsString("password_hash"),
+			DigestHA1:    section.GetAsString("digest_ha1"),
+			Roles:        splitList(section.GetAsString("roles")),
+		}
+	}
+
+	c.lock.Lock()
+	c.items = items
+	c.lock.Unlock()
+}
+
+// AddUser method adds or replaces the user.
+// Parameters:
+//   - user  *UserCredential  the user credential.
+func (c *MemoryUserStore) AddUser(user *UserCredential) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.items[user.Username] = user
+}
+
+// RemoveUser method removes the user.
+// Parameters:
+//   - username  string  the user login.
+func (c *MemoryUserStore) RemoveUser(username string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.items, username)
+}
+
+// Lookup method finds the user by login.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - username       string  the user login.
+//
+// Returns: *UserCredential, error
+// the user or nil when the user is unknown.
+func (c *MemoryUserStore) Lookup(correlationId string, username string) (*UserCredential, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.items[username], nil
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/x509"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	services "github.com/pip-services3-go/pip-services3-rpc-go/services"
+)
+
+// InvalidCertificateErrorCode is returned for client certificates that were not verified by the server
+const InvalidCertificateErrorCode = "INVALID_CERTIFICATE"
+
+// MtlsRoleRule maps client certificates matching the pattern to a role
+type MtlsRoleRule struct {
+	// Certificate field: spiffe, uri, cn, dns, email, ou or org.
+	Field string
+	// Glob pattern of the field value (see path.Match), i.e. "spiffe://example.org/ns/*/sa/billing".
+	Pattern string
+	// Role granted to matching clients.
+	Role string
+}
+
+/*
+MtlsAuthManager is an interceptor that authenticates services by client certificates
+verified by HttpEndpoint with mutual TLS (see "options.client_auth_type") and puts
+the client into request context (see WithUser), so RoleAuthManager can be used
+for service-to-service authorization.
+
+The user id is the SPIFFE ID from URI SANs, or the subject common name, or the first DNS SAN.
+User properties contain "subject", "cn", "spiffe_id", "dns_names", "emails", "uris",
+"ous", "orgs", "issuer" and "serial_number" of the certificate.
+Roles are granted by rules that match certificate fields with glob patterns.
+
+Configuration parameters:
+
+  - roles:                   role rules
+    - [role]:                comma-separated list of patterns as [field]:[glob], where field is
+                             spiffe, uri, cn, dns, email, ou or org,
+                             i.e. "spiffe:spiffe://example.org/ns/prod/sa/*,cn:billing"
+  - options:
+    - legacy_context:        also put the legacy "user" and "user_id" values into request context (default: false)
+
+Example:
+
+	mtlsAuth := auth.NewMtlsAuthManager()
+	mtlsAuth.Configure(cconf.NewConfigParamsFromTuples(
+		"roles.admin", "spiffe:spiffe://example.org/ns/ops/*",
+		"roles.billing", "cn:billing-*,ou:payments",
+	))
+	roleAuth := &auth.RoleAuthManager{}
+
+	service.RegisterInterceptor("", mtlsAuth.Authenticate())
+	service.RegisterRouteWithAuth("post", "/invoices", nil, roleAuth.UserInRole("billing"), service.createInvoice)
+*/
+type MtlsAuthManager struct {
+	// Rules to grant roles.
+	Rules []*MtlsRoleRule
+	// Puts the legacy "user" and "user_id" values into request context.
+	LegacyContext bool
+}
+
+// NewMtlsAuthManager creates a new instance of the manager.
+// Returns: *MtlsAuthManager
+func NewMtlsAuthManager() *MtlsAuthManager {
+	return &MtlsAuthManager{
+		Rules: make([]*MtlsRoleRule, 0),
+	}
+}
+
+// Configure method are configures the manager by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *MtlsAuthManager) Configure(config *cconf.ConfigParams) {
+	roles := config.GetSection("roles")
+	rules := make([]*MtlsRoleRule, 0)
+
+	names := roles.Keys()
+	sort.Strings(names)
+	for _, role := range names {
+		for _, pattern := range splitList(roles.GetAsString(role)) {
+			field := "spiffe"
+			if index := strings.Index(pattern, ":"); index > 0 && !strings.HasPrefix(pattern, "spiffe://") {
+				field, pattern = strings.ToLower(pattern[:index]), pattern[index+1:]
+			}
+			rules = append(rules, &MtlsRoleRule{Field: field, Pattern: pattern, Role: role})
+		}
+	}
+	c.Rules = rules
+	c.LegacyContext = config.GetAsBooleanWithDefault("options.legacy_context", c.LegacyContext)
+}
+
+// Authenticate method returns an interceptor that authenticates clients by verified certificates
+// and puts them into request context. Requests without client certificates are passed anonymously,
+// use Signed or other auth managers to require authentication.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *MtlsAuthManager) Authenticate() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.authenticate(res, req, next, false)
+	}
+}
+
+// Signed method returns an interceptor that requires a verified client certificate
+// and puts the client into request context.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *MtlsAuthManager) Signed() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.authenticate(res, req, next, true)
+	}
+}
+
+func (c *MtlsAuthManager) authenticate(res http.ResponseWriter, req *http.Request, next http.HandlerFunc, required bool) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		if required {
+			services.HttpResponseSender.SendError(res, req,
+				cerr.NewUnauthorizedError("", "NOT_SIGNED",
+					"Client certificate is required to perform this operation").WithStatus(401))
+			return
+		}
+		next.ServeHTTP(res, req)
+		return
+	}
+
+	// Certificates are verified only with verify_client_cert_if_given or require_and_verify_client_cert
+	if len(req.TLS.VerifiedChains) == 0 {
+		services.HttpResponseSender.SendError(res, req,
+			cerr.NewUnauthorizedError("", InvalidCertificateErrorCode,
+				"Client certificate is not verified").WithStatus(401))
+		return
+	}
+
+	principal := c.CertificateToPrincipal(req.TLS.PeerCertificates[0])
+	next.ServeHTTP(res, req.WithContext(withUser(req.Context(), principal, c.LegacyContext)))
+}
+
+// CertificateToPrincipal method maps the client certificate to a user with roles granted by rules.
+// Parameters:
+//   - cert  *x509.Certificate  the verified client certificate.
+//
+// Returns: *Principal
+func (c *MtlsAuthManager) CertificateToPrincipal(cert *x509.Certificate) *Principal {
+	fields := certificateFields(cert)
+
+	id := ""
+	for _, field := range []string{"spiffe", "cn", "dns"} {
+		if len(fields[field]) > 0 {
+			id = fields[field][0]
+			break
+		}
+	}
+
+	roles := make([]string, 0)
+	for _, rule := range c.Rules {
+		if contains(roles, rule.Role) {
+			continue
+		}
+		for _, value := range fields[rule.Field] {
+			if matched, _ := path.Match(rule.Pattern, value); matched {
+				roles = append(roles, rule.Role)
+				break
+			}
+		}
+	}
+
+	spiffeId := ""
+	if len(fields["spiffe"]) > 0 {
+		spiffeId = fields["spiffe"][0]
+	}
+	properties := cdata.NewAnyValueMapFromTuples(
+		"subject", cert.Subject.String(),
+		"cn", cert.Subject.CommonName,
+		"spiffe_id", spiffeId,
+		"dns_names", toInterfaces(fields["dns"]),
+		"emails", toInterfaces(fields["email"]),
+		"uris", toInterfaces(fields["uri"]),
+		"ous", toInterfaces(fields["ou"]),
+		"orgs", toInterfaces(fields["org"]),
+		"issuer", cert.Issuer.String(),
+		"serial_number", cert.SerialNumber.String(),
+	)
+	return NewPrincipal(id, roles, properties)
+}
+
+// Collects values of certificate fields used by rules
+func certificateFields(cert *x509.Certificate) map[string][]string {
+	fields := map[string][]string{
+		"dns":   cert.DNSNames,
+		"email": cert.EmailAddresses,
+		"ou":    cert.Subject.OrganizationalUnit,
+		"org":   cert.Subject.Organization,
+	}
+	if cert.Subject.CommonName != "" {
+		fields["cn"] = []string{cert.Subject.CommonName}
+	}
+	for _, uri := range cert.URIs {
+		fields["uri"] = append(fields["uri"], uri.String())
+		if uri.Scheme == "spiffe" {
+			fields["spiffe"] = append(fields["spiffe"], uri.String())
+		}
+	}
+	return fields
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
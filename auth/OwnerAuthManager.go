@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
-	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
 	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 	services "github.com/pip-services3-go/pip-services3-rpc-go/services"
 )
@@ -18,7 +17,7 @@ func (c *OwnerAuthManager) Owner(idParam string) func(res http.ResponseWriter, r
 	}
 	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 
-		_, ok := req.Context().Value("user").(cdata.AnyValueMap)
+		user, ok := UserFromRequest(req)
 
 		if !ok {
 			services.HttpResponseSender.SendError(
@@ -34,8 +33,7 @@ func (c *OwnerAuthManager) Owner(idParam string) func(res http.ResponseWriter, r
 				userId = mux.Vars(req)[idParam]
 			}
 
-			reqUserId, ok := req.Context().Value("user_id").(string)
-			if !ok || reqUserId != userId {
+			if user.Id == "" || user.Id != userId {
 				services.HttpResponseSender.SendError(
 					res, req,
 					cerr.NewUnauthorizedError(
@@ -56,7 +54,7 @@ func (c *OwnerAuthManager) OwnerOrAdmin(idParam string) func(res http.ResponseWr
 	}
 	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 
-		user, ok := req.Context().Value("user").(cdata.AnyValueMap)
+		user, ok := UserFromRequest(req)
 
 		if !ok {
 			services.HttpResponseSender.SendError(
@@ -72,18 +70,9 @@ func (c *OwnerAuthManager) OwnerOrAdmin(idParam string) func(res http.ResponseWr
 			if userId == "" {
 				userId = mux.Vars(req)[idParam]
 			}
-			roles := user.GetAsArray("roles")
-			admin := false
-			for _, role := range roles.Value() {
-				r, ok := role.(string)
-				if ok && r == "admin" {
-					admin = true
-					break
-				}
-			}
+			admin := user.HasRole("admin")
 
-			reqUserId, ok := req.Context().Value("user_id").(string)
-			if !ok || reqUserId != userId && !admin {
+			if (user.Id == "" || user.Id != userId) && !admin {
 				services.HttpResponseSender.SendError(
 					res, req,
 					cerr.NewUnauthorizedError("",
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+)
+
+/*
+Principal is the authenticated user of a request put into request context
+by authentication interceptors and read by BasicAuthManager, RoleAuthManager and OwnerAuthManager.
+
+WithUser also sets the user id for services.UserIdFromRequest, used by access log,
+maintenance mode and rate limits. The legacy "user" (cdata.AnyValueMap with "id" and "roles")
+and "user_id" context values are set by WithLegacyUser, used by auth managers
+with "options.legacy_context" turned on.
+UserFromContext falls back to them when they were set by custom middleware.
+
+Example:
+
+	principal := auth.NewPrincipal("123", []string{"admin"}, nil)
+	req = req.WithContext(auth.WithUser(req.Context(), principal))
+	...
+	if user, ok := auth.UserFromRequest(req); ok && user.HasRole("admin") {
+		...
+	}
+*/
+type Principal struct {
+	// Unique user id.
+	Id string
+	// User roles.
+	Roles []string
+	// Additional user properties, i.e. token claims.
+	Properties *cdata.AnyValueMap
+}
+
+type principalKey struct{}
+
+// NewPrincipal creates a new authenticated user.
+// Parameters:
+//   - id          string             a unique user id.
+//   - roles       []string           user roles.
+//   - properties  *cdata.AnyValueMap (optional) additional user properties.
+//
+// Returns: *Principal
+func NewPrincipal(id string, roles []string, properties *cdata.AnyValueMap) *Principal {
+	if roles == nil {
+		roles = []string{}
+	}
+	if properties == nil {
+		properties = cdata.NewEmptyAnyValueMap()
+	}
+	return &Principal{
+		Id:         id,
+		Roles:      roles,
+		Properties: properties,
+	}
+}
+
+// HasRole checks if the user has the role.
+func (c *Principal) HasRole(role string) bool {
+	for _, userRole := range c.Roles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyRole checks if the user has at least one of the roles.
+func (c *Principal) HasAnyRole(roles []string) bool {
+	for _, role := range roles {
+		if c.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts the user into the legacy "user" context value
+func (c *Principal) toUserMap() cdata.AnyValueMap {
+	user := cdata.NewAnyValueMap(c.Properties.Value())
+	roles := make([]interface{}, len(c.Roles))
+	for i, role := range c.Roles {
+		roles[i] = role
+	}
+	user.Put("id", c.Id)
+	user.Put("roles", roles)
+	return *user
+}
+
+// WithUser returns a copy of the context that carries the authenticated user.
+// Parameters:
+//   - ctx        context.Context  a parent context.
+//   - principal  *Principal       the authenticated user.
+//
+// Returns: context.Context
+func WithUser(ctx context.Context, principal *Principal) context.Context {
+	ctx = context.WithValue(ctx, principalKey{}, principal)
+	return services.ContextWithUserId(ctx, principal.Id)
+}
+
+// WithLegacyUser returns a copy of the context that carries the authenticated user
+// and also the legacy "user" and "user_id" values with plain string keys
+// for handlers that still read them directly.
+// Parameters:
+//   - ctx        context.Context  a parent context.
+//   - principal  *Principal       the authenticated user.
+//
+// Returns: context.Context
+func WithLegacyUser(ctx context.Context, principal *Principal) context.Context {
+	ctx = WithUser(ctx, principal)
+	ctx = context.WithValue(ctx, "user", principal.toUserMap())
+	return context.WithValue(ctx, "user_id", principal.Id)
+}
+
+// Puts the user into the context with or without the legacy values
+func withUser(ctx context.Context, principal *Principal, legacy bool) context.Context {
+	if legacy {
+		return WithLegacyUser(ctx, principal)
+	}
+	return WithUser(ctx, principal)
+}
+
+// UserFromContext gets the authenticated user carried by the context.
+// Parameters:
+//   - ctx  context.Context  a context.
+//
+// Returns: *Principal, bool
+// the user and true or nil and false when the request is not authenticated.
+func UserFromContext(ctx context.Context) (*Principal, bool) {
+	if principal, ok := ctx.Value(principalKey{}).(*Principal); ok && principal != nil {
+		return principal, true
+	}
+
+	// Fallback to legacy values set by custom middleware
+	var user *cdata.AnyValueMap
+	switch value := ctx.Value("user").(type) {
+	case cdata.AnyValueMap:
+		user = cdata.NewAnyValueMap(value.Value())
+	case *cdata.AnyValueMap:
+		if value == nil {
+			return nil, false
+		}
+		user = value
+	default:
+		return nil, false
+	}
+
+	id, ok := ctx.Value("user_id").(string)
+	if !ok {
+		id = user.GetAsString("id")
+	}
+	roles := make([]string, 0)
+	if userRoles := user.GetAsNullableArray("roles"); userRoles != nil {
+		for _, role := range userRoles.Value() {
+			if r, ok := role.(string); ok {
+				roles = append(roles, r)
+			}
+		}
+	}
+	return NewPrincipal(id, roles, user), true
+}
+
+// UserFromRequest gets the authenticated user of the request.
+// Parameters:
+//   - req  *http.Request  an HTTP request.
+//
+// Returns: *Principal, bool
+// the user and true or nil and false when the request is not authenticated.
+func UserFromRequest(req *http.Request) (*Principal, bool) {
+	return UserFromContext(req.Context())
+}
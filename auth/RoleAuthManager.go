@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"strings"
 
-	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
 	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 	services "github.com/pip-services3-go/pip-services3-rpc-go/services"
 )
@@ -15,34 +14,14 @@ type RoleAuthManager struct {
 func (c *RoleAuthManager) UserInRoles(roles []string) func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 
-		user, ok := req.Context().Value("user").(cdata.AnyValueMap)
+		user, ok := UserFromRequest(req)
 		if !ok {
 			services.HttpResponseSender.SendError(
 				res, req,
 				cerr.NewUnauthorizedError("", "NOT_SIGNED",
 					"User must be signed in to perform this operation").WithStatus(401))
 		} else {
-			authorized := false
-			userRoles := user.GetAsArray("roles")
-
-			if userRoles == nil {
-				services.HttpResponseSender.SendError(
-					res, req,
-					cerr.NewUnauthorizedError("", "NOT_SIGNED",
-						"User must be signed in to perform this operation").WithStatus(401))
-				return
-			}
-
-			for _, role := range roles {
-				for _, userRole := range userRoles.Value() {
-					r, ok := userRole.(string)
-					if ok && role == r {
-						authorized = true
-					}
-				}
-			}
-
-			if !authorized {
+			if !user.HasAnyRole(roles) {
 				services.HttpResponseSender.SendError(
 					res, req,
 					cerr.NewUnauthorizedError(
@@ -12,6 +12,7 @@ import (
 // See HttpEndpoint
 // See HeartbeatRestService
 // See StatusRestService
+// See MetricsRestService
 type DefaultRpcFactory struct {
 	cbuild.Factory
 }
@@ -24,9 +25,11 @@ func NewDefaultRpcFactory() *DefaultRpcFactory {
 	httpEndpointDescriptor := cref.NewDescriptor("pip-services", "endpoint", "http", "*", "1.0")
 	statusServiceDescriptor := cref.NewDescriptor("pip-services", "status-service", "http", "*", "1.0")
 	heartbeatServiceDescriptor := cref.NewDescriptor("pip-services", "heartbeat-service", "http", "*", "1.0")
+	metricsServiceDescriptor := cref.NewDescriptor("pip-services", "metrics-service", "http", "*", "1.0")
 
 	c.RegisterType(httpEndpointDescriptor, services.NewHttpEndpoint)
 	c.RegisterType(heartbeatServiceDescriptor, services.NewHeartbeatRestService)
 	c.RegisterType(statusServiceDescriptor, services.NewStatusRestService)
+	c.RegisterType(metricsServiceDescriptor, services.NewMetricsRestService)
 	return &c
 }
@@ -0,0 +1,307 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+	clog "github.com/pip-services3-go/pip-services3-components-go/log"
+)
+
+const (
+	// CircuitOpenErrorCode is a code of error returned when calls are rejected by open circuit breaker
+	CircuitOpenErrorCode = "CIRCUIT_OPEN"
+
+	// CircuitClosed is a state when all calls are allowed
+	CircuitClosed = "closed"
+	// CircuitOpen is a state when all calls are rejected
+	CircuitOpen = "open"
+	// CircuitHalfOpen is a state when limited number of trial calls are allowed
+	CircuitHalfOpen = "half-open"
+)
+
+/*
+CircuitBreaker protects clients from waiting on failed remote services.
+
+After too many failures the circuit opens and all calls fail fast with
+CIRCUIT_OPEN error. When the cool-down window passes the circuit becomes
+half-open and lets a limited number of trial calls through. Successful trial
+calls close the circuit, a failed one opens it again.
+
+Transport errors, timeouts and responses with 5xx status codes are counted as failures.
+
+Configuration parameters:
+
+  - options:
+  - circuit_breaker:
+  - enabled:                 turns the circuit breaker on (default: false)
+  - failure_threshold:       number of consecutive failures to open the circuit, 0 to disable (default: 5)
+  - failure_rate:            failure rate from 0 to 1 within the window to open the circuit, 0 to disable (default: 0.5)
+  - min_requests:            minimum number of calls within the window to calculate failure rate (default: 10)
+  - window:                  window to calculate failure rate in milliseconds (default: 60 sec)
+  - cool_down:               time the circuit stays open in milliseconds (default: 30 sec)
+  - half_open_max_calls:     number of trial calls in half-open state (default: 1)
+  - name:                    name used in log messages and counters (default: rest_client)
+
+Counters:
+
+  - <name>.circuit_open         number of times the circuit was opened
+  - <name>.circuit_half_open    number of times the circuit was half-opened
+  - <name>.circuit_closed       number of times the circuit was closed
+  - <name>.circuit_rejected     number of calls rejected by open circuit
+*/
+type CircuitBreaker struct {
+	// Turns the circuit breaker on.
+	Enabled bool
+	// Name used in log messages and counters.
+	Name string
+	// Number of consecutive failures to open the circuit.
+	FailureThreshold int
+	// Failure rate within the window to open the circuit.
+	FailureRate float64
+	// Minimum number of calls within the window to calculate failure rate.
+	MinRequests int
+	// Window to calculate failure rate.
+	Window time.Duration
+	// Time the circuit stays open.
+	CoolDown time.Duration
+	// Number of trial calls in half-open state.
+	HalfOpenMaxCalls int
+
+	logger   *clog.CompositeLogger
+	counters *ccount.CompositeCounters
+
+	lock                sync.Mutex
+	state               string
+	consecutiveFailures int
+	windowStart         time.Time
+	windowCalls         int
+	windowFailures      int
+	openedAt            time.Time
+	halfOpenCalls       int
+	halfOpenSuccesses   int
+}
+
+// NewCircuitBreaker creates a new instance of the circuit breaker.
+// Parameters:
+//   - logger    *clog.CompositeLogger      (optional) a logger to report state transitions.
+//   - counters  *ccount.CompositeCounters  (optional) counters to report state transitions.
+//
+// Returns: *CircuitBreaker
+func NewCircuitBreaker(logger *clog.CompositeLogger, counters *ccount.CompositeCounters) *CircuitBreaker {
+	return &CircuitBreaker{
+		Enabled:          false,
+		Name:             "rest_client",
+		FailureThreshold: 5,
+		FailureRate:      0.5,
+		MinRequests:      10,
+		Window:           60 * time.Second,
+		CoolDown:         30 * time.Second,
+		HalfOpenMaxCalls: 1,
+		logger:           logger,
+		counters:         counters,
+		state:            CircuitClosed,
+	}
+}
+
+// Configure method are configures the circuit breaker by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *CircuitBreaker) Configure(config *cconf.ConfigParams) {
+	config = config.GetSection("options.circuit_breaker")
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Enabled = config.GetAsBooleanWithDefault("enabled", c.Enabled)
+	c.Name = config.GetAsStringWithDefault("name", c.Name)
+	c.FailureThreshold = config.GetAsIntegerWithDefault("failure_threshold", c.FailureThreshold)
+	c.FailureRate = config.GetAsDoubleWithDefault("failure_rate", c.FailureRate)
+	c.MinRequests = config.GetAsIntegerWithDefault("min_requests", c.MinRequests)
+	c.Window = time.Duration(config.GetAsLongWithDefault("window", int64(c.Window/time.Millisecond))) * time.Millisecond
+	c.CoolDown = time.Duration(config.GetAsLongWithDefault("cool_down", int64(c.CoolDown/time.Millisecond))) * time.Millisecond
+	c.HalfOpenMaxCalls = config.GetAsIntegerWithDefault("half_open_max_calls", c.HalfOpenMaxCalls)
+	if c.HalfOpenMaxCalls < 1 {
+		c.HalfOpenMaxCalls = 1
+	}
+}
+
+// State returns the current state of the circuit: "closed", "open" or "half-open".
+func (c *CircuitBreaker) State() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.state
+}
+
+// Reset method are forces the circuit into closed state and clears collected statistics.
+func (c *CircuitBreaker) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.state = CircuitClosed
+	c.resetStats(time.Now())
+}
+
+// Allow method are checks if a call can be made.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//
+// Returns: error
+// CIRCUIT_OPEN error when the call is rejected or nil otherwise.
+func (c *CircuitBreaker) Allow(correlationId string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	if c.state == CircuitOpen && now.Sub(c.openedAt) >= c.CoolDown {
+		c.transition(correlationId, CircuitHalfOpen)
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		c.reject(correlationId)
+		return cerr.NewInvocationError(correlationId, CircuitOpenErrorCode,
+			"Circuit breaker is open, calls to REST service are suspended").
+			WithDetails("name", c.Name).
+			WithDetails("retry_after", (c.CoolDown - now.Sub(c.openedAt)).Milliseconds()).
+			WithStatus(503)
+	case CircuitHalfOpen:
+		if c.halfOpenCalls >= c.HalfOpenMaxCalls {
+			c.reject(correlationId)
+			return cerr.NewInvocationError(correlationId, CircuitOpenErrorCode,
+				"Circuit breaker is half-open, trial calls to REST service are in progress").
+				WithDetails("name", c.Name).
+				WithStatus(503)
+		}
+		c.halfOpenCalls++
+	}
+	return nil
+}
+
+// Complete method are records an outcome of the call allowed by Allow method.
+// Calls cancelled by the caller are not counted.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - err            error   (optional) an error returned by the call.
+func (c *CircuitBreaker) Complete(correlationId string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.Enabled {
+		return
+	}
+
+	outcome := circuitOutcome(err)
+	if c.state == CircuitHalfOpen {
+		if c.halfOpenCalls > 0 {
+			c.halfOpenCalls--
+		}
+		switch outcome {
+		case circuitFailure:
+			c.transition(correlationId, CircuitOpen)
+		case circuitSuccess:
+			c.halfOpenSuccesses++
+			if c.halfOpenSuccesses >= c.HalfOpenMaxCalls {
+				c.transition(correlationId, CircuitClosed)
+			}
+		}
+		return
+	}
+
+	if c.state != CircuitClosed || outcome == circuitIgnored {
+		return
+	}
+
+	now := time.Now()
+	if c.Window > 0 && now.Sub(c.windowStart) > c.Window {
+		c.windowStart = now
+		c.windowCalls = 0
+		c.windowFailures = 0
+	}
+	c.windowCalls++
+
+	if outcome == circuitSuccess {
+		c.consecutiveFailures = 0
+		return
+	}
+
+	c.consecutiveFailures++
+	c.windowFailures++
+
+	if c.FailureThreshold > 0 && c.consecutiveFailures >= c.FailureThreshold {
+		c.transition(correlationId, CircuitOpen)
+		return
+	}
+	if c.FailureRate > 0 && c.windowCalls >= c.MinRequests &&
+		float64(c.windowFailures)/float64(c.windowCalls) >= c.FailureRate {
+		c.transition(correlationId, CircuitOpen)
+	}
+}
+
+func (c *CircuitBreaker) transition(correlationId string, state string) {
+	if c.state == state {
+		return
+	}
+	previous := c.state
+	c.state = state
+
+	now := time.Now()
+	switch state {
+	case CircuitOpen:
+		c.openedAt = now
+		c.logger.Warn(correlationId, "Circuit breaker %s changed state from %s to open", c.Name, previous)
+		c.counters.IncrementOne(c.Name + ".circuit_open")
+	case CircuitHalfOpen:
+		c.halfOpenCalls = 0
+		c.halfOpenSuccesses = 0
+		c.logger.Info(correlationId, "Circuit breaker %s changed state from %s to half-open", c.Name, previous)
+		c.counters.IncrementOne(c.Name + ".circuit_half_open")
+	case CircuitClosed:
+		c.resetStats(now)
+		c.logger.Info(correlationId, "Circuit breaker %s changed state from %s to closed", c.Name, previous)
+		c.counters.IncrementOne(c.Name + ".circuit_closed")
+	}
+}
+
+func (c *CircuitBreaker) reject(correlationId string) {
+	c.logger.Trace(correlationId, "Circuit breaker %s rejected the call", c.Name)
+	c.counters.IncrementOne(c.Name + ".circuit_rejected")
+}
+
+func (c *CircuitBreaker) resetStats(now time.Time) {
+	c.consecutiveFailures = 0
+	c.windowStart = now
+	c.windowCalls = 0
+	c.windowFailures = 0
+	c.halfOpenCalls = 0
+	c.halfOpenSuccesses = 0
+}
+
+const (
+	circuitSuccess = iota
+	circuitFailure
+	circuitIgnored
+)
+
+func circuitOutcome(err error) int {
+	if err == nil {
+		return circuitSuccess
+	}
+	var appErr *cerr.ApplicationError
+	if !errors.As(err, &appErr) {
+		return circuitFailure
+	}
+	if appErr.Code == CancelledErrorCode || appErr.Code == CircuitOpenErrorCode {
+		return circuitIgnored
+	}
+	if appErr.Status >= 500 {
+		return circuitFailure
+	}
+	return circuitSuccess
+}
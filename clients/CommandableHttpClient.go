@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"reflect"
 
 	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
@@ -93,8 +94,23 @@ func NewCommandableHttpClient(baseRoute string) *CommandableHttpClient {
 // Returns: result interface{}, err error
 // result or error.
 func (c *CommandableHttpClient) CallCommand(prototype reflect.Type, name string, correlationId string, params *cdata.AnyValueMap) (result interface{}, err error) {
-	timing := c.Instrument(correlationId, c.BaseRoute+"."+name)
-	cRes, cErr := c.Call(prototype, "post", name, correlationId, nil, params.Value())
+	return c.CallCommandWithContext(context.Background(), prototype, name, correlationId, params)
+}
+
+// CallCommandWithContext is calls a remote method via HTTP commadable protocol
+// and propagates deadline and cancellation of the given context.
+// Parameters:
+//   - ctx    context.Context   a context to control the call lifetime.
+//   - prototype reflect.Type type of returned data
+//   - name        string      a name of the command to call.
+//   - correlationId  string   (optional) transaction id to trace execution through call chain.
+//   - params     cdata.StringValueMap       command parameters.
+// Returns: result interface{}, err error
+// result or error.
+func (c *CommandableHttpClient) CallCommandWithContext(ctx context.Context, prototype reflect.Type, name string,
+	correlationId string, params *cdata.AnyValueMap) (result interface{}, err error) {
+	ctx, timing := c.InstrumentWithContext(ctx, correlationId, c.BaseRoute+"."+name)
+	cRes, cErr := c.CallWithContext(ctx, prototype, "post", name, correlationId, nil, params.Value())
 	timing.EndTiming(cErr)
-    return cRes, cErr
+	return cRes, cErr
 }
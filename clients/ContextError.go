@@ -0,0 +1,43 @@
+package clients
+
+import (
+	"context"
+	"errors"
+
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+)
+
+const (
+	// TimeoutErrorCode is a code of error returned when call deadline was exceeded
+	TimeoutErrorCode = "TIMEOUT"
+	// CancelledErrorCode is a code of error returned when call was cancelled by the caller
+	CancelledErrorCode = "CANCELLED"
+)
+
+// NewContextError creates an application error for a call aborted by its context.
+// Exceeded deadlines are reported as TIMEOUT errors with 504 status code,
+// explicit cancellations are reported as CANCELLED errors with 499 status code.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - ctxErr  error   an error returned by context.Err()
+//
+// Returns: *cerr.ApplicationError
+// the NoResponse application error
+func NewContextError(correlationId string, ctxErr error) *cerr.ApplicationError {
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		return &cerr.ApplicationError{
+			Category:      cerr.NoResponse,
+			CorrelationId: correlationId,
+			Code:          TimeoutErrorCode,
+			Message:       "Call to REST service timed out",
+			Status:        504,
+		}
+	}
+	return &cerr.ApplicationError{
+		Category:      cerr.NoResponse,
+		CorrelationId: correlationId,
+		Code:          CancelledErrorCode,
+		Message:       "Call to REST service was cancelled",
+		Status:        499,
+	}
+}
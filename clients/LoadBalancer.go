@@ -0,0 +1,238 @@
+package clients
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+)
+
+const (
+	// LoadBalancingNone disables load balancing, the first resolved connection is used
+	LoadBalancingNone = "none"
+	// LoadBalancingRoundRobin selects endpoints one by one
+	LoadBalancingRoundRobin = "round_robin"
+	// LoadBalancingRandom selects random endpoints
+	LoadBalancingRandom = "random"
+	// LoadBalancingLeastOutstanding selects endpoints with the least number of calls in progress
+	LoadBalancingLeastOutstanding = "least_outstanding"
+)
+
+/*
+LoadBalancer distributes client calls across multiple endpoints.
+
+Endpoints that fail a number of consecutive calls or health checks
+are ejected for a configured time. When all endpoints are ejected
+the balancer falls back to use all of them.
+
+Configuration parameters:
+
+  - options:
+  - load_balancing:          balancing strategy: none, round_robin, random or least_outstanding (default: none)
+  - eject_failures:          number of consecutive failures to eject an endpoint, 0 to disable (default: 3)
+  - eject_time:              time an endpoint stays ejected in milliseconds (default: 30 sec)
+*/
+type LoadBalancer struct {
+	// Balancing strategy.
+	Strategy string
+	// Number of consecutive failures to eject an endpoint.
+	EjectFailures int
+	// Time an endpoint stays ejected.
+	EjectTime time.Duration
+
+	lock      sync.Mutex
+	endpoints []*balancedEndpoint
+	next      int
+}
+
+type balancedEndpoint struct {
+	uri          string
+	outstanding  int
+	failures     int
+	ejectedUntil time.Time
+}
+
+// NewLoadBalancer creates a new instance of the load balancer.
+// Returns: *LoadBalancer
+func NewLoadBalancer() *LoadBalancer {
+	return &LoadBalancer{
+		Strategy:      LoadBalancingNone,
+		EjectFailures: 3,
+		EjectTime:     30 * time.Second,
+		endpoints:     make([]*balancedEndpoint, 0),
+	}
+}
+
+// Configure method are configures the load balancer by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *LoadBalancer) Configure(config *cconf.ConfigParams) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Strategy = strings.ToLower(config.GetAsStringWithDefault("options.load_balancing", c.Strategy))
+	c.EjectFailures = config.GetAsIntegerWithDefault("options.eject_failures", c.EjectFailures)
+	c.EjectTime = time.Duration(config.GetAsLongWithDefault("options.eject_time",
+		int64(c.EjectTime/time.Millisecond))) * time.Millisecond
+}
+
+// IsEnabled checks if calls shall be balanced across multiple endpoints.
+func (c *LoadBalancer) IsEnabled() bool {
+	return c.Strategy != "" && c.Strategy != LoadBalancingNone
+}
+
+// SetEndpoints method are replaces the list of balanced endpoints.
+// Statistics of endpoints that remain in the list are preserved.
+// Parameters:
+//   - uris  []string  endpoint URIs.
+func (c *LoadBalancer) SetEndpoints(uris []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	endpoints := make([]*balancedEndpoint, 0, len(uris))
+	for _, uri := range uris {
+		var endpoint *balancedEndpoint
+		for _, existing := range c.endpoints {
+			if existing.uri == uri {
+				endpoint = existing
+				break
+			}
+		}
+		if endpoint == nil {
+			endpoint = &balancedEndpoint{uri: uri}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	c.endpoints = endpoints
+}
+
+// Endpoints returns URIs of all balanced endpoints.
+func (c *LoadBalancer) Endpoints() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	uris := make([]string, len(c.endpoints))
+	for i, endpoint := range c.endpoints {
+		uris[i] = endpoint.uri
+	}
+	return uris
+}
+
+// Select method are chooses an endpoint for the next call.
+// Every selected endpoint must be released by Release method after the call.
+// Returns: uri string, ok bool
+// URI of the selected endpoint and false when no endpoints are available.
+func (c *LoadBalancer) Select() (uri string, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.endpoints) == 0 {
+		return "", false
+	}
+
+	now := time.Now()
+	candidates := make([]*balancedEndpoint, 0, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		if !now.Before(endpoint.ejectedUntil) {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	// Fall back to all endpoints when every one is ejected
+	if len(candidates) == 0 {
+		candidates = c.endpoints
+	}
+
+	var selected *balancedEndpoint
+	switch c.Strategy {
+	case LoadBalancingRandom:
+		selected = candidates[rand.Intn(len(candidates))]
+	case LoadBalancingLeastOutstanding:
+		start := c.next % len(candidates)
+		c.next++
+		for i := 0; i < len(candidates); i++ {
+			endpoint := candidates[(start+i)%len(candidates)]
+			if selected == nil || endpoint.outstanding < selected.outstanding {
+				selected = endpoint
+			}
+		}
+	default:
+		selected = candidates[c.next%len(candidates)]
+		c.next++
+	}
+
+	selected.outstanding++
+	return selected.uri, true
+}
+
+// Release method are records an outcome of the call to the endpoint returned by Select method.
+// Parameters:
+//   - uri     string  URI of the endpoint.
+//   - failed  bool    true if the call failed.
+func (c *LoadBalancer) Release(uri string, failed bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	endpoint := c.find(uri)
+	if endpoint == nil {
+		return
+	}
+	if endpoint.outstanding > 0 {
+		endpoint.outstanding--
+	}
+	c.record(endpoint, failed)
+}
+
+// Report method are records an outcome of a health check for the endpoint.
+// Endpoints with failed health checks are ejected immediately.
+// Parameters:
+//   - uri      string  URI of the endpoint.
+//   - healthy  bool    true if the endpoint is healthy.
+func (c *LoadBalancer) Report(uri string, healthy bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	endpoint := c.find(uri)
+	if endpoint == nil {
+		return
+	}
+	if healthy {
+		endpoint.failures = 0
+		endpoint.ejectedUntil = time.Time{}
+	} else {
+		endpoint.ejectedUntil = time.Now().Add(c.EjectTime)
+	}
+}
+
+// IsEjected checks if the endpoint is currently ejected.
+// Parameters:
+//   - uri  string  URI of the endpoint.
+func (c *LoadBalancer) IsEjected(uri string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	endpoint := c.find(uri)
+	return endpoint != nil && time.Now().Before(endpoint.ejectedUntil)
+}
+
+func (c *LoadBalancer) find(uri string) *balancedEndpoint {
+	for _, endpoint := range c.endpoints {
+		if endpoint.uri == uri {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+func (c *LoadBalancer) record(endpoint *balancedEndpoint, failed bool) {
+	if !failed {
+		endpoint.failures = 0
+		return
+	}
+	endpoint.failures++
+	if c.EjectFailures > 0 && endpoint.failures >= c.EjectFailures {
+		endpoint.failures = 0
+		endpoint.ejectedUntil = time.Now().Add(c.EjectTime)
+	}
+}
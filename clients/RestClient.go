@@ -2,21 +2,26 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/json"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	neturl "net/url"
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
 	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
 	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	ccon "github.com/pip-services3-go/pip-services3-components-go/connect"
 	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
 	clog "github.com/pip-services3-go/pip-services3-components-go/log"
 	ctrace "github.com/pip-services3-go/pip-services3-components-go/trace"
@@ -58,7 +63,78 @@ Configuration parameters:
 
   - retries:               number of retries (default: 3)
 
-  - connectTimeout:        connection timeout in milliseconds (default: 10 sec)
+  - retry_initial_delay:   delay before the first retry in milliseconds (default: 100)
+
+  - retry_max_delay:       maximum delay between retries in milliseconds (default: 10 sec)
+
+  - retry_multiplier:      exponential backoff multiplier (default: 2)
+
+  - retry_jitter:          randomize retry delays using full jitter (default: true)
+
+  - retry_max_elapsed:     maximum time spent on retries in milliseconds (default: 60 sec)
+
+  - retry_statuses:        comma-separated retriable status codes (default: 429,502,503,504)
+
+  - retry_non_idempotent:  retry POST and PATCH calls, use WithIdempotent to allow it per call (default: false)
+
+  - circuit_breaker:
+
+  - enabled:               turns on circuit breaker (default: false)
+
+  - failure_threshold:     consecutive failures to open the circuit (default: 5)
+
+  - failure_rate:          failure rate within the window to open the circuit (default: 0.5)
+
+  - min_requests:          minimum calls within the window to calculate failure rate (default: 10)
+
+  - window:                failure rate window in milliseconds (default: 60 sec)
+
+  - cool_down:             time the circuit stays open in milliseconds (default: 30 sec)
+
+  - half_open_max_calls:   number of trial calls in half-open state (default: 1)
+
+  - load_balancing:        balancing across all resolved connections: none, round_robin, random, least_outstanding (default: none)
+
+  - eject_failures:        consecutive failures to eject a balanced endpoint (default: 3)
+
+  - eject_time:            time a failed endpoint stays ejected in milliseconds (default: 30 sec)
+
+  - resolve_interval:      interval to re-resolve balanced connections in milliseconds, 0 to disable (default: 0)
+
+  - health_route:          (optional) route to check health of balanced endpoints, i.e. "/heartbeat"
+
+  - health_interval:       interval of health checks in milliseconds (default: 10 sec)
+
+  - content_type:          content type of requests and preferred content type of responses:
+    application/json, application/xml, application/x-www-form-urlencoded, application/msgpack,
+    application/x-protobuf or any type registered in HttpCodecs (default: application/json)
+
+  - compression:
+  - enabled:               advertise br, gzip and deflate encodings and decompress responses (default: true)
+
+  - connect_timeout:       connection timeout in milliseconds (default: 10 sec), connectTimeout is also supported
+
+  - keep_alive:            TCP keep-alive period in milliseconds (default: 30 sec)
+
+  - disable_keep_alives:   disable reuse of connections between requests (default: false)
+
+  - max_idle_conns:        maximum number of idle connections across all hosts (default: 100)
+
+  - max_idle_conns_per_host: maximum number of idle connections per host (default: 10)
+
+  - max_conns_per_host:    maximum number of connections per host, 0 for no limit (default: 0)
+
+  - idle_conn_timeout:     time an idle connection stays in the pool in milliseconds (default: 90 sec)
+
+  - tls_handshake_timeout: TLS handshake timeout in milliseconds (default: 10 sec)
+
+  - response_header_timeout: time to wait for response headers in milliseconds, 0 for no limit (default: 0)
+
+  - expect_continue_timeout: time to wait for 100-continue response in milliseconds (default: 1 sec)
+
+  - http2_enabled:         attempt to use HTTP/2 (default: true)
+
+  - proxy:                 proxy URL, "env" to use HTTP_PROXY environment variables or "none" (default: env)
 
   - timeout:               invocation timeout in milliseconds (default: 10 sec)
 
@@ -127,6 +203,12 @@ type RestClient struct {
 	BaseRoute string
 	//The number of retries.
 	Retries int
+	//The policy that decides when and how failed calls are retried.
+	RetryPolicy IRetryPolicy
+	//The circuit breaker that suspends calls to failed service.
+	CircuitBreaker *CircuitBreaker
+	//The load balancer that distributes calls across resolved connections.
+	LoadBalancer *LoadBalancer
 	//The default headers to be added to every request.
 	Headers cdata.StringValueMap
 	//The connection timeout in milliseconds.
@@ -140,6 +222,14 @@ type RestClient struct {
 
 	enableExtendTls       bool
 	certificateServerName string
+	contentType           string
+	compression           bool
+
+	resolveInterval time.Duration
+	healthRoute     string
+	healthInterval  time.Duration
+	balancingDone   chan struct{}
+	balancingWg     sync.WaitGroup
 }
 
 // NewRestClient creates new instance of RestClient
@@ -171,9 +261,15 @@ func NewRestClient() *RestClient {
 	rc.Tracer = ctrace.NewCompositeTracer(nil)
 	rc.Options = *cconf.NewEmptyConfigParams()
 	rc.Retries = 1
+	rc.RetryPolicy = NewDefaultRetryPolicy()
+	rc.CircuitBreaker = NewCircuitBreaker(rc.Logger, rc.Counters)
+	rc.LoadBalancer = NewLoadBalancer()
+	rc.healthInterval = 10 * time.Second
 	rc.Headers = *cdata.NewEmptyStringValueMap()
 	rc.ConnectTimeout = 10000
 	rc.passCorrelationId = "query"
+	rc.contentType = "application/json"
+	rc.compression = true
 
 	rc.ITlsConfigurator = &rc
 	return &rc
@@ -195,9 +291,22 @@ func (c *RestClient) Configure(config *cconf.ConfigParams) {
 	c.Options = *c.Options.Override(config.GetSection("options"))
 	c.Retries = config.GetAsIntegerWithDefault("options.retries", c.Retries)
 	c.ConnectTimeout = config.GetAsIntegerWithDefault("options.connectTimeout", c.ConnectTimeout)
+	c.ConnectTimeout = config.GetAsIntegerWithDefault("options.connect_timeout", c.ConnectTimeout)
 	c.Timeout = config.GetAsIntegerWithDefault("options.timeout", c.Timeout)
+	if configurable, ok := c.RetryPolicy.(cconf.IConfigurable); ok {
+		configurable.Configure(config)
+	}
+	c.CircuitBreaker.Configure(config)
+	c.LoadBalancer.Configure(config)
+	c.resolveInterval = time.Duration(config.GetAsLongWithDefault("options.resolve_interval",
+		int64(c.resolveInterval/time.Millisecond))) * time.Millisecond
+	c.healthRoute = config.GetAsStringWithDefault("options.health_route", c.healthRoute)
+	c.healthInterval = time.Duration(config.GetAsLongWithDefault("options.health_interval",
+		int64(c.healthInterval/time.Millisecond))) * time.Millisecond
 	c.BaseRoute = config.GetAsStringWithDefault("base_route", c.BaseRoute)
 	c.passCorrelationId = config.GetAsStringWithDefault("options.correlation_id", c.passCorrelationId)
+	c.contentType = config.GetAsStringWithDefault("options.content_type", c.contentType)
+	c.compression = config.GetAsBooleanWithDefault("options.compression.enabled", c.compression)
 
 	c.enableExtendTls = config.GetAsBooleanWithDefault("options.enable_extend_tls", c.enableExtendTls)
 	c.certificateServerName = config.GetAsStringWithDefault("options.certificate_server_name", c.certificateServerName)
@@ -228,6 +337,27 @@ func (c *RestClient) Instrument(correlationId string, name string) *service.Inst
 		c.Logger, c.Counters, counterTiming, traceTiming)
 }
 
+// InstrumentWithContext method are adds instrumentation to log calls and measure call time
+// and starts a child span of the trace carried by the context.
+// Pass the returned context to CallWithContext to send the span to the service.
+// Parameters:
+//   - ctx    context.Context  a context, i.e. the context of the incoming request.
+//   - correlationId  string   (optional) transaction id to trace execution through call chain.
+//   - name    string          a method name.
+//
+// Return a context that carries the started span and Timing object to end the time measurement.
+func (c *RestClient) InstrumentWithContext(ctx context.Context, correlationId string,
+	name string) (context.Context, *service.InstrumentTiming) {
+	ctx, span := service.StartSpan(ctx)
+	c.Logger.Trace(correlationId, "Calling %s method in trace %s span %s", name, span.TraceId, span.SpanId)
+	c.Counters.IncrementOne(name + ".call_count")
+	counterTiming := c.Counters.BeginTiming(name + ".call_time")
+	traceTiming := c.Tracer.BeginTrace(correlationId, name, "")
+	timing := service.NewInstrumentTiming(correlationId, name, "call",
+		c.Logger, c.Counters, counterTiming, traceTiming)
+	return ctx, timing.WithSpan(span)
+}
+
 // InstrumentError method are dds instrumentation to error handling.
 //   - correlationId   string  (optional) transaction id to trace execution through call chain.
 //   - name   string           a method name.
@@ -261,46 +391,40 @@ func (c *RestClient) Open(correlationId string) error {
 		return nil
 	}
 
-	connection, _, conErr := c.ConnectionResolver.Resolve(correlationId)
-	if conErr != nil {
-		return conErr
-	}
-
-	c.Uri = connection.Uri()
-	localClient := http.Client{}
-	localClient.Timeout = (time.Duration)(c.Timeout) * time.Millisecond
-	c.Client = &localClient
-
-	if connection.Protocol() == "https" {
-		certificates, err := c.ITlsConfigurator.GetCertificates()
-		if err != nil {
-			return err
-		}
-
-		transport := &http.Transport{
-			TLSClientConfig: &tls.Config{
-				// TLS versions below 1.2 are considered insecure
-				// see https://www.rfc-editor.org/rfc/rfc7525.txt for details
-				MinVersion:   tls.VersionTLS12,
-				Certificates: certificates,
-				ServerName:   c.certificateServerName,
-			},
+	var connection *ccon.ConnectionParams
+	if c.LoadBalancer.IsEnabled() {
+		connections, _, conErr := c.ConnectionResolver.ResolveAll(correlationId)
+		if conErr != nil {
+			return conErr
 		}
-
-		caCertPool, err := c.ITlsConfigurator.GetCaCert()
-		if err != nil {
-			return err
+		if len(connections) == 0 {
+			return cerr.NewConfigError(correlationId, "NO_CONNECTION", "Connection for REST client is not defined")
 		}
-		if caCertPool != nil {
-			transport.TLSClientConfig.RootCAs = caCertPool
+		connection = connections[0]
+		c.LoadBalancer.SetEndpoints(c.connectionUris(connections))
+	} else {
+		var conErr error
+		connection, _, conErr = c.ConnectionResolver.Resolve(correlationId)
+		if conErr != nil {
+			return conErr
 		}
+	}
 
-		c.Client.Transport = transport
+	transport, err := c.createTransport(connection)
+	if err != nil {
+		return err
+	}
 
+	c.Uri = connection.Uri()
+	c.Client = &http.Client{
+		Timeout:   (time.Duration)(c.Timeout) * time.Millisecond,
+		Transport: transport,
 	}
-	if c.Client == nil {
-		ex := cerr.NewConnectionError(correlationId, "CANNOT_CONNECT", "Connection to REST service failed").WithDetails("url", c.Uri)
-		return ex
+
+	if c.LoadBalancer.IsEnabled() && (c.resolveInterval > 0 || c.healthRoute != "") {
+		c.balancingDone = make(chan struct{})
+		c.balancingWg.Add(1)
+		go c.balance(correlationId, c.Client, c.balancingDone)
 	}
 
 	return nil
@@ -313,6 +437,11 @@ func (c *RestClient) Open(correlationId string) error {
 // Retruns: error
 // error or nil no errors occured.
 func (c *RestClient) Close(correlationId string) error {
+	if c.balancingDone != nil {
+		close(c.balancingDone)
+		c.balancingWg.Wait()
+		c.balancingDone = nil
+	}
 	if c.Client != nil {
 		c.Logger.Debug(correlationId, "Closed REST service at %s", c.Uri)
 		c.Client = nil
@@ -384,6 +513,189 @@ func (c *RestClient) AddPagingParams(params *cdata.StringValueMap, paging *cdata
 	return params
 }
 
+// Creates HTTP transport with connection pool settings from configuration options
+func (c *RestClient) createTransport(connection *ccon.ConnectionParams) (*http.Transport, error) {
+	options := &c.Options
+	millis := func(key string, defaultValue int64) time.Duration {
+		return time.Duration(options.GetAsLongWithDefault(key, defaultValue)) * time.Millisecond
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(c.ConnectTimeout) * time.Millisecond,
+		KeepAlive: millis("keep_alive", 30000),
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		DisableKeepAlives:     options.GetAsBooleanWithDefault("disable_keep_alives", false),
+		MaxIdleConns:          options.GetAsIntegerWithDefault("max_idle_conns", 100),
+		MaxIdleConnsPerHost:   options.GetAsIntegerWithDefault("max_idle_conns_per_host", 10),
+		MaxConnsPerHost:       options.GetAsIntegerWithDefault("max_conns_per_host", 0),
+		IdleConnTimeout:       millis("idle_conn_timeout", 90000),
+		TLSHandshakeTimeout:   millis("tls_handshake_timeout", 10000),
+		ResponseHeaderTimeout: millis("response_header_timeout", 0),
+		ExpectContinueTimeout: millis("expect_continue_timeout", 1000),
+		ForceAttemptHTTP2:     options.GetAsBooleanWithDefault("http2_enabled", true),
+		// Compressed responses are decoded by the client itself
+		DisableCompression: true,
+	}
+
+	proxy := options.GetAsStringWithDefault("proxy", "env")
+	switch strings.ToLower(proxy) {
+	case "", "env":
+		transport.Proxy = http.ProxyFromEnvironment
+	case "none":
+		transport.Proxy = nil
+	default:
+		proxyUrl, err := neturl.Parse(proxy)
+		if err != nil {
+			return nil, cerr.NewConfigError("", "INVALID_PROXY", "Proxy URL is invalid").
+				WithDetails("proxy", proxy).WithCause(err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if connection.Protocol() == "https" {
+		certificates, err := c.ITlsConfigurator.GetCertificates()
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			// TLS versions below 1.2 are considered insecure
+			// see https://www.rfc-editor.org/rfc/rfc7525.txt for details
+			MinVersion:   tls.VersionTLS12,
+			Certificates: certificates,
+			ServerName:   c.certificateServerName,
+		}
+
+		caCertPool, err := c.ITlsConfigurator.GetCaCert()
+		if err != nil {
+			return nil, err
+		}
+		if caCertPool != nil {
+			transport.TLSClientConfig.RootCAs = caCertPool
+		}
+	}
+
+	return transport, nil
+}
+
+// Adds tracing of connection pool usage to the request
+func (c *RestClient) tracePool(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				c.Counters.IncrementOne("rest_client.connections.reused")
+				if info.WasIdle {
+					c.Counters.Stats("rest_client.connections.idle_time", float32(info.IdleTime.Milliseconds()))
+				}
+			} else {
+				c.Counters.IncrementOne("rest_client.connections.created")
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+func (c *RestClient) connectionUris(connections []*ccon.ConnectionParams) []string {
+	uris := make([]string, 0, len(connections))
+	for _, connection := range connections {
+		if connection != nil && connection.Uri() != "" {
+			uris = append(uris, connection.Uri())
+		}
+	}
+	return uris
+}
+
+func (c *RestClient) selectUri() (uri string, balanced bool) {
+	if c.LoadBalancer.IsEnabled() {
+		if uri, ok := c.LoadBalancer.Select(); ok {
+			return uri, true
+		}
+	}
+	return c.Uri, false
+}
+
+// Periodically re-resolves balanced connections and checks their health
+func (c *RestClient) balance(correlationId string, client *http.Client, done chan struct{}) {
+	defer c.balancingWg.Done()
+
+	var resolveTick, healthTick <-chan time.Time
+	if c.resolveInterval > 0 {
+		ticker := time.NewTicker(c.resolveInterval)
+		defer ticker.Stop()
+		resolveTick = ticker.C
+	}
+	if c.healthRoute != "" && c.healthInterval > 0 {
+		ticker := time.NewTicker(c.healthInterval)
+		defer ticker.Stop()
+		healthTick = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-resolveTick:
+			connections, _, err := c.ConnectionResolver.ResolveAll(correlationId)
+			if err != nil {
+				c.Logger.Warn(correlationId, "Failed to re-resolve REST connections: %s", err.Error())
+				continue
+			}
+			uris := c.connectionUris(connections)
+			if len(uris) > 0 {
+				c.LoadBalancer.SetEndpoints(uris)
+			}
+		case <-healthTick:
+			c.checkHealth(correlationId, client, done)
+		}
+	}
+}
+
+func (c *RestClient) checkHealth(correlationId string, client *http.Client, done chan struct{}) {
+	route := c.healthRoute
+	if !strings.HasPrefix(route, "/") {
+		route = "/" + route
+	}
+
+	timeout := time.Duration(c.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	for _, uri := range c.LoadBalancer.Endpoints() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		healthy := false
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri+route, nil)
+		if err == nil {
+			resp, respErr := client.Do(req)
+			if respErr == nil {
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+				healthy = resp.StatusCode < 400
+			}
+		}
+		cancel()
+
+		if !healthy && c.LoadBalancer.IsEjected(uri) {
+			continue
+		}
+		if !healthy {
+			c.Logger.Warn(correlationId, "REST endpoint %s failed health check and was ejected", uri)
+		}
+		c.LoadBalancer.Report(uri, healthy)
+	}
+}
+
 func (c *RestClient) createRequestRoute(route string) string {
 	builder := ""
 
@@ -415,6 +727,194 @@ func (c *RestClient) createRequestRoute(route string) string {
 // result object or error.
 func (c *RestClient) Call(prototype reflect.Type, method string, route string, correlationId string, params *cdata.StringValueMap,
 	data interface{}) (result interface{}, err error) {
+	return c.CallWithContext(context.Background(), prototype, method, route, correlationId, params, data)
+}
+
+// CallWithContext method are calls a remote method via HTTP/REST protocol
+// and propagates deadline and cancellation of the given context.
+// When the context is done the call and all following retries are aborted
+// and TIMEOUT or CANCELLED error is returned.
+// Parameters:
+//   - ctx    context.Context   a context to control the call lifetime.
+//   - prototype reflect.Type type for convert JSON result. Set nil for return raw JSON string
+//   - method 	string           HTTP method: "get", "head", "post", "put", "delete"
+//   - route   string          a command route. Base route will be added to this route
+//   - correlationId  string    (optional) transaction id to trace execution through call chain.
+//   - params  cdata.StringValueMap          (optional) query parameters.
+//   - data   interface{}           (optional) body object.
+//
+// Returns:  result interface{}, err error
+// result object or error.
+func (c *RestClient) CallWithContext(ctx context.Context, prototype reflect.Type, method string, route string,
+	correlationId string, params *cdata.StringValueMap, data interface{}) (result interface{}, err error) {
+
+	r, contentType, err := c.callRaw(ctx, method, route, correlationId, params, data)
+	if err != nil || r == nil {
+		return nil, err
+	}
+
+	if prototype != nil {
+		codec := service.HttpCodecs.ForContentType(contentType)
+		if _, ok := codec.(*service.JsonCodec); ok {
+			return ConvertComandResult(r, prototype)
+		}
+		if prototype.Kind() == reflect.Ptr {
+			prototype = prototype.Elem()
+		}
+		value := reflect.New(prototype).Interface()
+		if decErr := codec.Decode(r, value); decErr != nil {
+			return nil, decErr
+		}
+		return value, nil
+	}
+
+	return r, nil
+}
+
+// Calls remote method and returns raw response body with its content type
+func (c *RestClient) callRaw(ctx context.Context, method string, route string, correlationId string,
+	params *cdata.StringValueMap, data interface{}) (result []byte, contentType string, err error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !c.IsOpen() {
+		return nil, "", nil
+	}
+
+	codec := c.codec()
+	var body []byte
+	if data != nil {
+		body, err = codec.Encode(data)
+		if err != nil {
+			return nil, "", cerr.NewBadRequestError(correlationId, "ENCODE_ERROR", "Failed to encode request body").
+				WithDetails("content_type", codec.ContentType()).WithCause(err)
+		}
+	} else {
+		body = make([]byte, 0)
+	}
+
+	resp, err := c.send(ctx, method, route, correlationId, params, codec.ContentType(),
+		func() (io.Reader, error) { return bytes.NewBuffer(body), nil }, true)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 204 {
+		return nil, "", nil
+	}
+
+	contentType = resp.Header.Get("Content-Type")
+	r, rErr := ioutil.ReadAll(resp.Body)
+	if rErr != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, "", NewContextError(correlationId, ctxErr).WithCause(rErr)
+		}
+		eDesct := cerr.ErrorDescription{
+			Type:          "Application",
+			Category:      "Application",
+			Status:        resp.StatusCode,
+			Code:          "",
+			Message:       rErr.Error(),
+			CorrelationId: correlationId,
+		}
+		return nil, "", cerr.ApplicationErrorFactory.Create(&eDesct).WithCause(rErr)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, "", c.decodeError(resp.StatusCode, contentType, r)
+	}
+
+	return r, contentType, nil
+}
+
+// Returns codec to encode requests, JSON by default
+func (c *RestClient) codec() service.IHttpCodec {
+	return service.HttpCodecs.ForContentType(c.contentType)
+}
+
+// CallStream method are calls a remote method via HTTP/REST protocol
+// without buffering request and response bodies in memory.
+// The request body is sent as is with the given content type.
+// Calls with bodies that do not implement io.Seeker are never retried.
+// The body is never closed by the client: when it is an io.Closer (e.g. *os.File)
+// the caller remains responsible for closing it after the call returns.
+// On success the caller must close the response body.
+// Responses with 4xx and 5xx status codes are converted into errors.
+// Correlation id, retries and circuit breaker are applied the same way as in Call method.
+// Parameters:
+//   - ctx    context.Context   a context to control the call lifetime.
+//   - method 	string           HTTP method: "get", "head", "post", "put", "delete"
+//   - route   string          a command route. Base route will be added to this route
+//   - correlationId  string    (optional) transaction id to trace execution through call chain.
+//   - params  cdata.StringValueMap          (optional) query parameters.
+//   - body    io.Reader        (optional) request body.
+//   - contentType  string      (optional) content type of the request body.
+//
+// Returns:  result *StreamResponse, err error
+// streamed response or error.
+func (c *RestClient) CallStream(ctx context.Context, method string, route string, correlationId string,
+	params *cdata.StringValueMap, body io.Reader, contentType string) (result *StreamResponse, err error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if !c.IsOpen() {
+		return nil, cerr.NewInvalidStateError(correlationId, "NOT_OPENED", "REST client is not opened")
+	}
+
+	seeker, replayable := body.(io.Seeker)
+	if body == nil {
+		replayable = true
+	}
+	first := true
+	getBody := func() (io.Reader, error) {
+		if body == nil {
+			return nil, nil
+		}
+		if !first && seeker != nil {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		first = false
+		// Keep the transport from closing the body after the first attempt,
+		// otherwise seekable files cannot be rewound for retries.
+		if _, ok := body.(io.Closer); ok {
+			return ioutil.NopCloser(body), nil
+		}
+		return body, nil
+	}
+
+	resp, err := c.send(ctx, method, route, correlationId, params, contentType, getBody, replayable)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		r, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, c.decodeError(resp.StatusCode, resp.Header.Get("Content-Type"), r)
+	}
+
+	return &StreamResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       resp.Body,
+	}, nil
+}
+
+// Maximum size of error response read from streamed calls
+const maxErrorBodySize = 1024 * 1024
+
+// Sends request with retries, load balancing and circuit breaker
+// and returns the response with unread body
+func (c *RestClient) send(ctx context.Context, method string, route string, correlationId string,
+	params *cdata.StringValueMap, contentType string, getBody func() (io.Reader, error),
+	replayable bool) (resp *http.Response, err error) {
 
 	method = strings.ToUpper(method)
 	if params == nil {
@@ -434,94 +934,156 @@ func (c *RestClient) Call(prototype reflect.Type, method string, route string, c
 		}
 	}
 
-	url := c.Uri + route
+	if cbErr := c.CircuitBreaker.Allow(correlationId); cbErr != nil {
+		return nil, cbErr
+	}
+	defer func() {
+		if err == nil && resp != nil && resp.StatusCode >= 500 {
+			c.CircuitBreaker.Complete(correlationId, c.decodeError(resp.StatusCode, "", nil))
+		} else {
+			c.CircuitBreaker.Complete(correlationId, err)
+		}
+	}()
 
-	if !c.IsOpen() {
-		return nil, nil
+	retryPolicy := c.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = NewDefaultRetryPolicy()
 	}
-	var jsonStr []byte
-	if data != nil {
-		jsonStr, _ = json.Marshal(data)
-	} else {
-		jsonStr = make([]byte, 0)
+	var respErr error
+	startTime := time.Now()
+
+	// Continue the trace carried by the context or start a new one
+	span := service.SpanFromContext(ctx)
+	if span == nil {
+		span = service.NewSpanContext()
 	}
 
-	retries := c.Retries
-	var resp *http.Response
-	var respErr error
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, NewContextError(correlationId, ctxErr)
+		}
+
+		body, bodyErr := getBody()
+		if bodyErr != nil {
+			return nil, cerr.NewUnknownError(correlationId, "COMMUNICATION_ERROR", "Failed to read request body").WithCause(bodyErr)
+		}
 
-	for retries > 0 {
-		req, reqErr := http.NewRequest(method, url, bytes.NewBuffer(jsonStr))
+		uri, balanced := c.selectUri()
+		url := uri + route
+		req, reqErr := http.NewRequestWithContext(ctx, method, url, body)
 
 		if reqErr != nil {
+			if balanced {
+				c.LoadBalancer.Release(uri, false)
+			}
 			err = cerr.NewUnknownError(correlationId, "UNSUPPORTED_METHOD", "Method is not supported by REST client").WithDetails("verb", method).WithCause(reqErr)
 			return nil, err
 		}
 		// Set headers
-		req.Header.Set("Content-Type", "application/json")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Accept", c.accept())
+		if c.compression {
+			req.Header.Set("Accept-Encoding", service.AcceptEncodings)
+		}
 		if c.passCorrelationId == "headers" || c.passCorrelationId == "both" {
 			req.Header.Set("correlation_id", correlationId)
 		}
+		req.Header.Set(service.TraceParentHeader, span.TraceParent())
+		if span.TraceState != "" {
+			req.Header.Set(service.TraceStateHeader, span.TraceState)
+		}
 		//req.Header.Set("User-Agent", c.UserAgent)
 		for k, v := range c.Headers.Value() {
 			req.Header.Set(k, v)
 		}
 		// Try send request
-		resp, respErr = c.Client.Do(req)
+		resp, respErr = c.Client.Do(c.tracePool(req))
+		if balanced {
+			c.LoadBalancer.Release(uri, (respErr != nil && ctx.Err() == nil) || (resp != nil && resp.StatusCode >= 500))
+		}
 		if respErr != nil {
-
-			retries--
-			if retries == 0 {
-				err = cerr.NewUnknownError(correlationId, "COMMUNICATION_ERROR", "Unknown communication problem on REST client").WithCause(respErr)
-				return nil, err
+			// Do not retry calls aborted by the caller
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, NewContextError(correlationId, ctxErr).WithCause(respErr)
 			}
-			continue
 		}
-		break
-	}
 
-	if resp != nil {
-		defer resp.Body.Close()
-	}
+		if attempt >= c.Retries || !replayable {
+			break
+		}
+		retry, delay := retryPolicy.ShouldRetry(req, resp, respErr, attempt, time.Since(startTime))
+		if !retry {
+			break
+		}
 
-	if resp.StatusCode == 204 {
-		return nil, nil
+		if resp != nil {
+			// Release connection before the next attempt
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			resp = nil
+		}
+		c.Logger.Trace(correlationId, "Retrying call to %s in %d ms (attempt %d)", url, delay.Milliseconds(), attempt+1)
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, NewContextError(correlationId, ctx.Err())
+			case <-timer.C:
+			}
+		}
 	}
 
-	r, rErr := ioutil.ReadAll(resp.Body)
-	if rErr != nil {
-		eDesct := cerr.ErrorDescription{
-			Type:          "Application",
-			Category:      "Application",
-			Status:        resp.StatusCode,
-			Code:          "",
-			Message:       rErr.Error(),
-			CorrelationId: correlationId,
-		}
-		return nil, cerr.ApplicationErrorFactory.Create(&eDesct).WithCause(rErr)
+	if respErr != nil {
+		err = cerr.NewUnknownError(correlationId, "COMMUNICATION_ERROR", "Unknown communication problem on REST client").WithCause(respErr)
+		return nil, err
 	}
 
-	if resp.StatusCode >= 400 {
-		appErr := cerr.ApplicationError{}
-		json.Unmarshal(r, &appErr)
-		if appErr.Status == 0 && len(r) > 0 { // not standart Pip.Services error
-			values := make(map[string]interface{})
-			decodeErr := json.Unmarshal(r, &values)
-			if decodeErr != nil { // not json response
-				appErr.Message = (string)(r)
-			}
-			appErr.Details = values
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" && c.compression {
+		body, decErr := service.DecompressBody(encoding, resp.Body)
+		if decErr != nil {
+			resp.Body.Close()
+			err = cerr.NewUnknownError(correlationId, "COMMUNICATION_ERROR", "Failed to decompress response body").
+				WithDetails("encoding", encoding).WithCause(decErr)
+			return nil, err
 		}
-		appErr.Status = resp.StatusCode
-		return nil, &appErr
+		resp.Body = body
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
 	}
 
-	if prototype != nil {
-		return ConvertComandResult(r, prototype)
-	}
+	return resp, nil
+}
 
-	return r, rErr
+// Returns Accept header value that prefers configured content type
+func (c *RestClient) accept() string {
+	contentType := c.codec().ContentType()
+	if contentType == "application/json" {
+		return contentType
+	}
+	return contentType + ", application/json;q=0.9"
+}
 
+// Converts error response into application error
+func (c *RestClient) decodeError(status int, contentType string, r []byte) error {
+	codec := service.HttpCodecs.ForContentType(contentType)
+	appErr := cerr.ApplicationError{}
+	codec.Decode(r, &appErr)
+	if appErr.Status == 0 && len(r) > 0 { // not standart Pip.Services error
+		values := make(map[string]interface{})
+		decodeErr := codec.Decode(r, &values)
+		if decodeErr != nil { // not json response
+			appErr.Message = (string)(r)
+		}
+		appErr.Details = values
+	}
+	appErr.Status = status
+	return &appErr
 }
 
 func (c *RestClient) GetCertificates() ([]tls.Certificate, error) {
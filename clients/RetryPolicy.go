@@ -0,0 +1,248 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+)
+
+// IRetryPolicy defines a strategy used by RestClient to decide
+// whether a failed call attempt shall be repeated.
+type IRetryPolicy interface {
+	// ShouldRetry decides if the call shall be retried after a failed attempt.
+	// Parameters:
+	//   - req      *http.Request  the request sent in the last attempt.
+	//   - resp     *http.Response (optional) the received response, nil on transport errors.
+	//   - err      error          (optional) the transport error.
+	//   - attempt  int            the number of the last attempt starting from 1.
+	//   - elapsed  time.Duration  time elapsed since the first attempt.
+	// Returns: retry bool, delay time.Duration
+	// true to retry and the delay to wait before the next attempt.
+	ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int, elapsed time.Duration) (retry bool, delay time.Duration)
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks calls made with the returned context as safe to retry
+// regardless of the HTTP method. Use it for POST or PATCH calls to idempotent operations.
+// Parameters:
+//   - ctx  context.Context  a parent context.
+//
+// Returns: context.Context
+// the context with idempotency marker.
+func WithIdempotent(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// IsIdempotent checks if a request can be safely repeated.
+// GET, HEAD, OPTIONS, TRACE, PUT and DELETE requests are idempotent by definition,
+// other requests are idempotent only when marked with WithIdempotent.
+// Parameters:
+//   - req  *http.Request  a request to check.
+//
+// Returns: true if the request can be repeated.
+func IsIdempotent(req *http.Request) bool {
+	switch strings.ToUpper(req.Method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+/*
+DefaultRetryPolicy is a retry policy with exponential backoff and full jitter.
+
+It retries transport errors and responses with retriable status codes,
+honors Retry-After response headers and never retries non-idempotent
+calls unless they are explicitly allowed. Calls that failed to connect
+and were never sent, i.e. refused connections, are retried for any method.
+
+Configuration parameters:
+
+  - options:
+  - retry_initial_delay:     delay before the first retry in milliseconds (default: 100)
+  - retry_max_delay:         maximum delay between retries in milliseconds (default: 10 sec)
+  - retry_multiplier:        backoff multiplier (default: 2)
+  - retry_jitter:            randomize delays using full jitter (default: true)
+  - retry_max_elapsed:       maximum time spent on retries in milliseconds, 0 to disable (default: 60 sec)
+  - retry_statuses:          comma-separated list of retriable status codes (default: 429,502,503,504)
+  - retry_non_idempotent:    retry POST and PATCH calls (default: false)
+
+Example:
+
+	client := NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", "http://localhost:8080",
+		"options.retries", 5,
+		"options.retry_initial_delay", 200,
+		"options.retry_statuses", "503,504",
+	))
+*/
+type DefaultRetryPolicy struct {
+	// Delay before the first retry.
+	InitialDelay time.Duration
+	// Maximum delay between retries.
+	MaxDelay time.Duration
+	// Backoff multiplier.
+	Multiplier float64
+	// Randomize delays using full jitter.
+	Jitter bool
+	// Maximum time spent on retries, 0 to disable the limit.
+	MaxElapsedTime time.Duration
+	// Status codes that shall be retried.
+	RetryStatuses []int
+	// Retry calls that are not idempotent.
+	RetryNonIdempotent bool
+}
+
+// NewDefaultRetryPolicy creates a new instance of the retry policy with default settings.
+// Returns: *DefaultRetryPolicy
+func NewDefaultRetryPolicy() *DefaultRetryPolicy {
+	return &DefaultRetryPolicy{
+		InitialDelay:       100 * time.Millisecond,
+		MaxDelay:           10 * time.Second,
+		Multiplier:         2,
+		Jitter:             true,
+		MaxElapsedTime:     60 * time.Second,
+		RetryStatuses:      []int{429, 502, 503, 504},
+		RetryNonIdempotent: false,
+	}
+}
+
+// Configure method are configures the policy by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *DefaultRetryPolicy) Configure(config *cconf.ConfigParams) {
+	c.InitialDelay = time.Duration(config.GetAsLongWithDefault("options.retry_initial_delay",
+		int64(c.InitialDelay/time.Millisecond))) * time.Millisecond
+	c.MaxDelay = time.Duration(config.GetAsLongWithDefault("options.retry_max_delay",
+		int64(c.MaxDelay/time.Millisecond))) * time.Millisecond
+	c.Multiplier = config.GetAsDoubleWithDefault("options.retry_multiplier", c.Multiplier)
+	c.Jitter = config.GetAsBooleanWithDefault("options.retry_jitter", c.Jitter)
+	c.MaxElapsedTime = time.Duration(config.GetAsLongWithDefault("options.retry_max_elapsed",
+		int64(c.MaxElapsedTime/time.Millisecond))) * time.Millisecond
+	c.RetryNonIdempotent = config.GetAsBooleanWithDefault("options.retry_non_idempotent", c.RetryNonIdempotent)
+
+	statuses := config.GetAsNullableString("options.retry_statuses")
+	if statuses != nil {
+		c.RetryStatuses = make([]int, 0)
+		for _, status := range strings.Split(*statuses, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(status))
+			if err == nil {
+				c.RetryStatuses = append(c.RetryStatuses, code)
+			}
+		}
+	}
+}
+
+// ShouldRetry decides if the call shall be retried after a failed attempt.
+// See IRetryPolicy.ShouldRetry
+func (c *DefaultRetryPolicy) ShouldRetry(req *http.Request, resp *http.Response, err error,
+	attempt int, elapsed time.Duration) (retry bool, delay time.Duration) {
+
+	if req == nil || req.Context().Err() != nil {
+		return false, 0
+	}
+	if !c.RetryNonIdempotent && !IsIdempotent(req) && !IsNotSent(err) {
+		return false, 0
+	}
+
+	if err == nil {
+		if resp == nil || !c.isRetryStatus(resp.StatusCode) {
+			return false, 0
+		}
+	}
+
+	delay = c.backoff(attempt)
+	if resp != nil {
+		if retryAfter, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			delay = retryAfter
+		}
+	}
+
+	if c.MaxElapsedTime > 0 && elapsed+delay > c.MaxElapsedTime {
+		return false, 0
+	}
+	return true, delay
+}
+
+// IsNotSent checks if the transport error happened before the request was sent,
+// i.e. when the connection was refused, so the call can be safely repeated.
+// Parameters:
+//   - err  error  a transport error.
+//
+// Returns: true if the request was not sent.
+func IsNotSent(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (c *DefaultRetryPolicy) isRetryStatus(status int) bool {
+	for _, code := range c.RetryStatuses {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DefaultRetryPolicy) backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(c.InitialDelay) * math.Pow(c.Multiplier, float64(attempt-1))
+	if c.MaxDelay > 0 && delay > float64(c.MaxDelay) {
+		delay = float64(c.MaxDelay)
+	}
+	if c.Jitter && delay > 0 {
+		delay = rand.Float64() * delay
+	}
+	return time.Duration(delay)
+}
+
+// ParseRetryAfter parses a value of Retry-After header
+// specified either in seconds or as HTTP date.
+// Parameters:
+//   - value  string  the header value.
+//
+// Returns: delay time.Duration, ok bool
+// the delay to wait and true if the value was successfully parsed.
+func ParseRetryAfter(value string) (delay time.Duration, ok bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,30 @@
+package clients
+
+import (
+	"io"
+	"net/http"
+)
+
+// StreamResponse is a response of streamed REST call.
+// The Body must be closed by the caller to release the connection.
+type StreamResponse struct {
+	// HTTP status code
+	StatusCode int
+	// Response headers
+	Header http.Header
+	// Response body
+	Body io.ReadCloser
+}
+
+// ContentType returns content type of the response body
+func (c *StreamResponse) ContentType() string {
+	return c.Header.Get("Content-Type")
+}
+
+// Close method are closes the response body
+func (c *StreamResponse) Close() error {
+	if c.Body == nil {
+		return nil
+	}
+	return c.Body.Close()
+}
@@ -0,0 +1,107 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	service "github.com/pip-services3-go/pip-services3-rpc-go/services"
+)
+
+// DataPage is a typed data page returned by paginated calls.
+type DataPage[T any] struct {
+	// Total number of items, returned only when requested
+	Total *int64 `json:"total"`
+	// Items of the page
+	Data []T `json:"data"`
+}
+
+// ConvertResult method decodes JSON result into the typed value.
+// Empty and null results are converted into zero values.
+// Parameters:
+//   - data  []byte  raw JSON result.
+//
+// Returns: result T, err error
+func ConvertResult[T any](data []byte) (result T, err error) {
+	if len(data) == 0 || string(data) == "null" {
+		return result, nil
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// Call method calls a remote method via HTTP/REST protocol and returns typed result.
+// Parameters:
+//   - client  *RestClient    a client to make the call.
+//   - method 	string           HTTP method: "get", "head", "post", "put", "delete"
+//   - route   string          a command route. Base route will be added to this route
+//   - correlationId  string    (optional) transaction id to trace execution through call chain.
+//   - params  cdata.StringValueMap          (optional) query parameters.
+//   - data   interface{}           (optional) body object.
+//
+// Returns:  result T, err error
+// result or error.
+//
+// Example:
+//
+//	page, err := clients.Call[clients.DataPage[MyData]](&c.RestClient, "get", "/data", correlationId, params, nil)
+func Call[T any](client *RestClient, method string, route string, correlationId string,
+	params *cdata.StringValueMap, data interface{}) (result T, err error) {
+	return CallWithContext[T](context.Background(), client, method, route, correlationId, params, data)
+}
+
+// CallWithContext method calls a remote method via HTTP/REST protocol with the given context
+// and returns typed result.
+// See Call
+func CallWithContext[T any](ctx context.Context, client *RestClient, method string, route string,
+	correlationId string, params *cdata.StringValueMap, data interface{}) (result T, err error) {
+
+	raw, contentType, err := client.callRaw(ctx, method, route, correlationId, params, data)
+	if err != nil {
+		return result, err
+	}
+	return convertTypedResult[T](raw, contentType)
+}
+
+// CallCommand method calls a remote command via HTTP commandable protocol and returns typed result.
+// Parameters:
+//   - client  *CommandableHttpClient    a client to make the call.
+//   - name        string      a name of the command to call.
+//   - correlationId  string   (optional) transaction id to trace execution through call chain.
+//   - params     *cdata.AnyValueMap       command parameters.
+//
+// Returns: result T, err error
+// result or error.
+//
+// Example:
+//
+//	dummy, err := clients.CallCommand[*MyData](c.CommandableHttpClient, "get_data", correlationId, params)
+func CallCommand[T any](client *CommandableHttpClient, name string, correlationId string,
+	params *cdata.AnyValueMap) (result T, err error) {
+	return CallCommandWithContext[T](context.Background(), client, name, correlationId, params)
+}
+
+// CallCommandWithContext method calls a remote command via HTTP commandable protocol
+// with the given context and returns typed result.
+// See CallCommand
+func CallCommandWithContext[T any](ctx context.Context, client *CommandableHttpClient, name string,
+	correlationId string, params *cdata.AnyValueMap) (result T, err error) {
+
+	ctx, timing := client.InstrumentWithContext(ctx, correlationId, client.BaseRoute+"."+name)
+	raw, contentType, err := client.callRaw(ctx, "post", name, correlationId, nil, params.Value())
+	timing.EndTiming(err)
+	if err != nil {
+		return result, err
+	}
+	return convertTypedResult[T](raw, contentType)
+}
+
+// Decodes typed result using codec selected by response content type
+func convertTypedResult[T any](data []byte, contentType string) (result T, err error) {
+	codec := service.HttpCodecs.ForContentType(contentType)
+	if _, ok := codec.(*service.JsonCodec); ok || len(data) == 0 {
+		return ConvertResult[T](data)
+	}
+	err = codec.Decode(data, &result)
+	return result, err
+}
@@ -55,12 +55,20 @@ type HttpConnectionResolver struct {
 	ConnectionResolver ccon.ConnectionResolver
 	//The base credential resolver.
 	CredentialResolver cauth.CredentialResolver
+	//Allows port 0 to bind services to a port assigned by the system.
+	//It shall not be set for clients that cannot connect to port 0.
+	AllowZeroPort bool
+
+	references crefer.IReferences
 }
 
 // NewHttpConnectionResolver creates new instance NewHttpConnectionResolver
 // Returns pointer on NewHttpConnectionResolver
 func NewHttpConnectionResolver() *HttpConnectionResolver {
-	return &HttpConnectionResolver{*ccon.NewEmptyConnectionResolver(), *cauth.NewEmptyCredentialResolver()}
+	return &HttpConnectionResolver{
+		ConnectionResolver: *ccon.NewEmptyConnectionResolver(),
+		CredentialResolver: *cauth.NewEmptyCredentialResolver(),
+	}
 }
 
 // Configure method are configures component by passing configuration parameters.
@@ -75,6 +83,7 @@ func (c *HttpConnectionResolver) Configure(config *cconf.ConfigParams) {
 // Parameters:
 // 	 - references crefer.IReferences	references to locate the component dependencies.
 func (c *HttpConnectionResolver) SetReferences(references crefer.IReferences) {
+	c.references = references
 	c.ConnectionResolver.SetReferences(references)
 	c.CredentialResolver.SetReferences(references)
 }
@@ -96,8 +105,8 @@ func (c *HttpConnectionResolver) validateConnection(correlationId string, connec
 	if host == "" {
 		return cerr.NewConfigError(correlationId, "NO_HOST", "Connection host is not set")
 	}
-	port := connection.Port()
-	if port == 0 {
+	port := connection.GetAsNullableInteger("port")
+	if port == nil || (*port == 0 && !c.AllowZeroPort) {
 		return cerr.NewConfigError(correlationId, "NO_PORT", "Connection port is not set")
 	}
 	// Check HTTPS credentials
@@ -231,3 +240,55 @@ func (c *HttpConnectionResolver) Register(correlationId string) error {
 		return err
 	}
 }
+
+// RegisterConnection method are registers the given connection in all referenced discovery services.
+// It is used instead of Register method when the actual connection differs from the configured one,
+// for instance when the port was assigned by the system.
+// Parameters:
+// - correlationId  string   (optional) transaction id to trace execution through call chain.
+// - connection  *ccon.ConnectionParams   a connection to register.
+// Returns: error
+// nil if registered connection or error.
+func (c *HttpConnectionResolver) RegisterConnection(correlationId string, connection *ccon.ConnectionParams) error {
+	credential, err := c.CredentialResolver.Lookup(correlationId)
+	if err == nil {
+		err = c.validateConnection(correlationId, connection, credential)
+	}
+	if err != nil {
+		return err
+	}
+	return c.ConnectionResolver.Register(correlationId, connection)
+}
+
+// Unregister method are removes connections registered by Register method from referenced
+// discovery services that implement IUnregisterableDiscovery interface.
+// Other discovery services are skipped.
+// Parameters:
+// - correlationId  string   (optional) transaction id to trace execution through call chain.
+// Returns: error
+// nil if unregistered connection or error.
+func (c *HttpConnectionResolver) Unregister(correlationId string) error {
+	if c.references == nil {
+		return nil
+	}
+
+	discoveries := c.references.GetOptional(crefer.NewDescriptor("*", "discovery", "*", "*", "*"))
+	unregistered := make(map[string]bool)
+	for _, connection := range c.ConnectionResolver.GetAll() {
+		key := connection.DiscoveryKey()
+		if !connection.UseDiscovery() || key == "" || unregistered[key] {
+			continue
+		}
+		unregistered[key] = true
+		for _, discovery := range discoveries {
+			unregisterable, ok := discovery.(IUnregisterableDiscovery)
+			if !ok {
+				continue
+			}
+			if err := unregisterable.Unregister(correlationId, key, connection); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package connect
+
+import (
+	ccon "github.com/pip-services3-go/pip-services3-components-go/connect"
+)
+
+// IUnregisterableDiscovery is an optional interface of discovery services
+// that are able to remove previously registered connections.
+// It is used by HttpConnectionResolver to unregister services before shutdown.
+type IUnregisterableDiscovery interface {
+	// Unregister removes the connection registered under the given key.
+	// Parameters:
+	//   - correlationId  string                  (optional) transaction id to trace execution through call chain.
+	//   - key            string                  a key to uniquely identify the connection.
+	//   - connection     *ccon.ConnectionParams  the connection to remove.
+	// Returns: error
+	Unregister(correlationId string, key string, connection *ccon.ConnectionParams) error
+}
@@ -1,11 +1,24 @@
 module github.com/pip-services3-go/pip-services3-rpc-go
 
-go 1.16
+go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.6
 	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.8.0
 	github.com/pip-services3-go/pip-services3-commons-go v1.1.6
 	github.com/pip-services3-go/pip-services3-components-go v1.3.2
 	github.com/stretchr/testify v1.8.1
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/crypto v0.17.0
+	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/felixge/httpsnoop v1.0.1 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -2,7 +2,6 @@ package services
 
 import (
 	"bytes"
-	"encoding/json"
 	"io/ioutil"
 	"net/http"
 
@@ -148,7 +147,7 @@ func (c *CommandableHttpService) Register() {
 			req.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBuf))
 			//-------------------------
 			var params map[string]interface{} = make(map[string]interface{}, 0)
-			json.Unmarshal(bodyBuf, &params)
+			HttpCodecs.ForRequest(req).Decode(bodyBuf, &params)
 
 			urlParams := req.URL.Query()
 			for k, v := range urlParams {
@@ -160,7 +159,8 @@ func (c *CommandableHttpService) Register() {
 
 			correlationId := c.GetCorrelationId(req)
 			args := crun.NewParametersFromValue(params)
-			timing := c.Instrument(correlationId, c.BaseRoute+"."+command.Name())
+			ctx, timing := c.InstrumentWithContext(req.Context(), correlationId, c.BaseRoute+"."+command.Name())
+			req = req.WithContext(ctx)
 
 			execRes, execErr := command.Execute(correlationId, args)
 			timing.EndTiming(execErr)
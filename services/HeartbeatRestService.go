@@ -5,6 +5,7 @@ import (
 	"time"
 
 	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 )
 
 /*
@@ -14,7 +15,8 @@ The service responds on /heartbeat route (can be changed)
 with a string with the current time in UTC.
 
 This service route can be used to health checks by loadbalancers and
-container orchestrators.
+container orchestrators. When HTTP endpoint is closing the service
+responds with 503 status code.
 
 Configuration parameters:
 
@@ -93,5 +95,10 @@ func (c *HeartbeatRestService) Register() {
   @param res   an HTTP response
 */
 func (c *HeartbeatRestService) heartbeat(req *http.Request, res http.ResponseWriter) {
+	if endpoint, ok := c.Endpoint.(interface{ IsReady() bool }); ok && !endpoint.IsReady() {
+		c.SendError(res, req, cerr.NewInvalidStateError(c.GetCorrelationId(req), "NOT_READY",
+			"Service is shutting down").WithStatus(http.StatusServiceUnavailable))
+		return
+	}
 	c.SendResult(res, req, time.Now(), nil)
 }
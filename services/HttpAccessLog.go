@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	clog "github.com/pip-services3-go/pip-services3-components-go/log"
+)
+
+const (
+	// AccessLogJson writes access log records as JSON objects
+	AccessLogJson = "json"
+	// AccessLogCommon writes access log records in Common Log Format
+	AccessLogCommon = "common"
+	// AccessLogCombined writes access log records in Combined Log Format
+	AccessLogCombined = "combined"
+)
+
+/*
+HttpAccessLog writes records about requests served by HTTP endpoint into its logger.
+
+JSON records contain method, route template, path, status, response size, latency,
+client address, user agent, correlation id and trace id. Common and combined formats follow
+the conventions of Apache and Nginx servers. Failed requests with 5xx status codes
+are always logged regardless of sampling.
+
+Configuration parameters:
+
+  - options:
+  - access_log:
+  - enabled:                 turns on access logging (default: false)
+  - format:                  json, common or combined (default: json)
+  - sample_rate:             part of requests to log from 0 to 1 (default: 1)
+  - exclude_routes:          comma-separated list of routes not to log (default: heartbeat)
+*/
+type HttpAccessLog struct {
+	// Turns on access logging.
+	Enabled bool
+	// Format of records: json, common or combined.
+	Format string
+	// Part of requests to log from 0 to 1.
+	SampleRate float64
+	// Routes not to log.
+	ExcludeRoutes []string
+
+	logger *clog.CompositeLogger
+}
+
+// NewHttpAccessLog creates a new instance of the access log.
+// Parameters:
+//   - logger  *clog.CompositeLogger  a logger to write records to.
+//
+// Returns: *HttpAccessLog
+func NewHttpAccessLog(logger *clog.CompositeLogger) *HttpAccessLog {
+	if logger == nil {
+		logger = clog.NewCompositeLogger()
+	}
+	return &HttpAccessLog{
+		Enabled:       false,
+		Format:        AccessLogJson,
+		SampleRate:    1,
+		ExcludeRoutes: []string{"heartbeat"},
+		logger:        logger,
+	}
+}
+
+// Configure method are configures the access log by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *HttpAccessLog) Configure(config *cconf.ConfigParams) {
+	config = config.GetSection("options.access_log")
+
+	c.Enabled = config.GetAsBooleanWithDefault("enabled", c.Enabled)
+	c.Format = strings.ToLower(config.GetAsStringWithDefault("format", c.Format))
+	c.SampleRate = config.GetAsDoubleWithDefault("sample_rate", c.SampleRate)
+
+	if routes := config.GetAsNullableString("exclude_routes"); routes != nil {
+		c.ExcludeRoutes = make([]string, 0)
+		for _, route := range strings.Split(*routes, ",") {
+			route = strings.Trim(strings.TrimSpace(route), "/")
+			if route != "" {
+				c.ExcludeRoutes = append(c.ExcludeRoutes, route)
+			}
+		}
+	}
+}
+
+// Log writes a record about the served request
+func (c *HttpAccessLog) Log(req *http.Request, record *httpRequestRecord) {
+	if !c.Enabled || c.isExcluded(record) {
+		return
+	}
+	if record.status < 500 && c.SampleRate < 1 && rand.Float64() >= c.SampleRate {
+		return
+	}
+
+	var line string
+	switch c.Format {
+	case AccessLogCommon:
+		line = c.formatCommon(req, record)
+	case AccessLogCombined:
+		line = c.formatCommon(req, record) + " " + strconv.Quote(req.Referer()) + " " + strconv.Quote(req.UserAgent())
+	default:
+		line = c.formatJson(req, record)
+	}
+	c.logger.Info(record.correlationId, "%s", line)
+}
+
+func (c *HttpAccessLog) isExcluded(record *httpRequestRecord) bool {
+	route := strings.Trim(record.route, "/")
+	path := strings.Trim(record.path, "/")
+	for _, excluded := range c.ExcludeRoutes {
+		if route == excluded || path == excluded || strings.HasSuffix(path, "/"+excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *HttpAccessLog) formatJson(req *http.Request, record *httpRequestRecord) string {
+	entry := map[string]interface{}{
+		"time":           record.started.UTC().Format(time.RFC3339Nano),
+		"method":         record.method,
+		"route":          record.route,
+		"path":           record.path,
+		"status":         record.status,
+		"bytes":          record.responseSize,
+		"latency_ms":     float64(record.duration.Microseconds()) / 1000,
+		"client_address": HttpRequestDetector.DetectAddress(req),
+		"user_agent":     req.UserAgent(),
+		"correlation_id": record.correlationId,
+	}
+	if record.userId != "" {
+		entry["user_id"] = record.userId
+	}
+	if span := SpanFromRequest(req); span != nil {
+		entry["trace_id"] = span.TraceId
+	}
+	data, _ := json.Marshal(entry)
+	return string(data)
+}
+
+func (c *HttpAccessLog) formatCommon(req *http.Request, record *httpRequestRecord) string {
+	user := "-"
+	if record.userId != "" {
+		user = record.userId
+	}
+	size := "-"
+	if record.responseSize > 0 {
+		size = strconv.FormatInt(record.responseSize, 10)
+	}
+	return HttpRequestDetector.DetectAddress(req) + " - " + user +
+		" [" + record.started.Format("02/Jan/2006:15:04:05 -0700") + "] " +
+		strconv.Quote(record.method+" "+req.URL.RequestURI()+" "+req.Proto) + " " +
+		strconv.Itoa(record.status) + " " + size
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Request in progress tracked by HTTP endpoint
+type activeRequest struct {
+	method        string
+	path          string
+	correlationId string
+	started       time.Time
+}
+
+// Registry of HTTP requests in progress used to drain endpoints on shutdown
+type activeRequests struct {
+	lock     sync.Mutex
+	requests map[*activeRequest]struct{}
+}
+
+func newActiveRequests() *activeRequests {
+	return &activeRequests{
+		requests: make(map[*activeRequest]struct{}),
+	}
+}
+
+// Registers the request and returns a function to call when it is completed
+func (c *activeRequests) track(req *http.Request, correlationId string) (count int, done func() int) {
+	request := &activeRequest{
+		method:        req.Method,
+		path:          req.URL.Path,
+		correlationId: correlationId,
+		started:       time.Now(),
+	}
+
+	c.lock.Lock()
+	c.requests[request] = struct{}{}
+	count = len(c.requests)
+	c.lock.Unlock()
+
+	return count, func() int {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		delete(c.requests, request)
+		return len(c.requests)
+	}
+}
+
+func (c *activeRequests) count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.requests)
+}
+
+func (c *activeRequests) list() []*activeRequest {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	result := make([]*activeRequest, 0, len(c.requests))
+	for request := range c.requests {
+		result = append(result, request)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+)
+
+// RequestTooLargeErrorCode is a code of error returned when request body exceeds the size limit
+const RequestTooLargeErrorCode = "REQUEST_TOO_LARGE"
+
+// NewRequestTooLargeError creates an error for request body that exceeds the size limit.
+// The error is sent to clients with 413 status code.
+// Parameters:
+//   - correlationId  string  (optional) transaction id to trace execution through call chain.
+//   - maxSize        int64   the exceeded limit in bytes.
+//
+// Returns: *cerr.ApplicationError
+func NewRequestTooLargeError(correlationId string, maxSize int64) *cerr.ApplicationError {
+	return cerr.NewBadRequestError(correlationId, RequestTooLargeErrorCode,
+		"Request body exceeds the maximum allowed size").
+		WithDetails("max_size", maxSize).
+		WithStatus(http.StatusRequestEntityTooLarge)
+}
+
+// Checks if request carries multipart content such as file uploads
+func isMultipartRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && strings.HasPrefix(mediaType, "multipart/")
+}
+
+// Limits body of the request. Returns false and sends 413 error
+// when declared content length already exceeds the limit.
+func limitRequestBody(res http.ResponseWriter, req *http.Request, correlationId string, maxSize int64) bool {
+	if maxSize <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.ContentLength > maxSize {
+		res.Header().Set("Connection", "close")
+		HttpResponseSender.SendError(res, req, NewRequestTooLargeError(correlationId, maxSize))
+		return false
+	}
+	req.Body = &limitedBody{body: req.Body, remaining: maxSize, maxSize: maxSize, correlationId: correlationId}
+	return true
+}
+
+// Request body that fails with 413 error when more than maxSize bytes are read
+type limitedBody struct {
+	body          io.ReadCloser
+	remaining     int64
+	maxSize       int64
+	correlationId string
+	err           error
+}
+
+func (c *limitedBody) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// Read one byte over the limit to detect oversized bodies
+	if int64(len(p)) > c.remaining+1 {
+		p = p[:c.remaining+1]
+	}
+	n, err := c.body.Read(p)
+	if int64(n) <= c.remaining {
+		c.remaining -= int64(n)
+		c.err = err
+		return n, err
+	}
+	n = int(c.remaining)
+	c.remaining = 0
+	c.err = NewRequestTooLargeError(c.correlationId, c.maxSize)
+	return n, c.err
+}
+
+func (c *limitedBody) Close() error {
+	return c.body.Close()
+}
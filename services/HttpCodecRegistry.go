@@ -0,0 +1,173 @@
+package services
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+HttpCodecRegistry keeps codecs used to encode and decode HTTP bodies
+by their content types and negotiates content types from Accept headers.
+
+JSON, XML, form-urlencoded, msgpack and protobuf codecs are registered by default.
+JSON codec is used when content type is not specified or not supported.
+
+Example:
+
+	services.HttpCodecs.Register(NewMyCsvCodec(), "text/csv")
+
+	codec := services.HttpCodecs.Negotiate(req.Header.Get("Accept"))
+	data, err := codec.Encode(result)
+*/
+var HttpCodecs = NewHttpCodecRegistry()
+
+type HttpCodecRegistry struct {
+	lock         sync.RWMutex
+	codecs       map[string]IHttpCodec
+	defaultCodec IHttpCodec
+}
+
+// NewHttpCodecRegistry creates a new registry with default codecs
+func NewHttpCodecRegistry() *HttpCodecRegistry {
+	c := &HttpCodecRegistry{
+		codecs: make(map[string]IHttpCodec),
+	}
+	c.defaultCodec = NewJsonCodec()
+	c.Register(c.defaultCodec, "text/json")
+	c.Register(NewXmlCodec(), "text/xml")
+	c.Register(NewFormCodec())
+	c.Register(NewMsgpackCodec(), "application/x-msgpack")
+	c.Register(NewProtobufCodec(), "application/protobuf")
+	return c
+}
+
+// Register method are registers a codec for its content type and optional aliases.
+// Parameters:
+//   - codec    IHttpCodec  a codec to register.
+//   - aliases  ...string   additional content types handled by the codec.
+func (c *HttpCodecRegistry) Register(codec IHttpCodec, aliases ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.codecs[strings.ToLower(codec.ContentType())] = codec
+	for _, alias := range aliases {
+		c.codecs[strings.ToLower(alias)] = codec
+	}
+}
+
+// Get method returns a codec for the content type.
+// Parameters of the content type like charset are ignored.
+// Parameters:
+//   - contentType  string  a content type.
+//
+// Returns: codec IHttpCodec, ok bool
+// the codec and true if it was found.
+func (c *HttpCodecRegistry) Get(contentType string) (codec IHttpCodec, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	codec, ok = c.codecs[strings.ToLower(mediaType)]
+	return codec, ok
+}
+
+// Default returns the codec used when content type is not specified (JSON).
+func (c *HttpCodecRegistry) Default() IHttpCodec {
+	return c.defaultCodec
+}
+
+// ForContentType method returns a codec for the content type or the default codec.
+// Parameters:
+//   - contentType  string  a content type.
+func (c *HttpCodecRegistry) ForContentType(contentType string) IHttpCodec {
+	if codec, ok := c.Get(contentType); ok {
+		return codec
+	}
+	return c.defaultCodec
+}
+
+// ForRequest method returns a codec to decode the request body by its Content-Type header.
+// Parameters:
+//   - req  *http.Request  an HTTP request.
+func (c *HttpCodecRegistry) ForRequest(req *http.Request) IHttpCodec {
+	if req == nil {
+		return c.defaultCodec
+	}
+	return c.ForContentType(req.Header.Get("Content-Type"))
+}
+
+// ForResponse method returns a codec to encode the response to the request
+// negotiated by its Accept header.
+// Parameters:
+//   - req  *http.Request  an HTTP request.
+func (c *HttpCodecRegistry) ForResponse(req *http.Request) IHttpCodec {
+	if req == nil {
+		return c.defaultCodec
+	}
+	return c.Negotiate(req.Header.Get("Accept"))
+}
+
+// Negotiate method selects a codec acceptable by the client.
+// The media ranges are ordered by their quality values, wildcards select the default codec.
+// When the client accepts any media type, other codecs are selected only for the most preferred
+// media types, so browsers that list application/xml after text/html still get the default codec.
+// Parameters:
+//   - accept  string  a value of Accept header.
+//
+// Returns: the selected codec or the default codec when nothing matches.
+func (c *HttpCodecRegistry) Negotiate(accept string) IHttpCodec {
+	if strings.TrimSpace(accept) == "" {
+		return c.defaultCodec
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	wildcard := false
+	for _, r := range ranges {
+		if r.mediaType == "*/*" || strings.HasSuffix(r.mediaType, "/*") {
+			wildcard = true
+		}
+	}
+
+	for _, r := range ranges {
+		if r.mediaType == "*/*" || strings.HasSuffix(r.mediaType, "/*") {
+			return c.defaultCodec
+		}
+		if codec, ok := c.Get(r.mediaType); ok {
+			if wildcard && r.quality < ranges[0].quality {
+				return c.defaultCodec
+			}
+			return codec
+		}
+	}
+	return c.defaultCodec
+}
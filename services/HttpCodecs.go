@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+
+	cconv "github.com/pip-services3-go/pip-services3-commons-go/convert"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// JsonCodec encodes and decodes application/json bodies
+type JsonCodec struct{}
+
+// NewJsonCodec creates a new JSON codec
+func NewJsonCodec() *JsonCodec {
+	return &JsonCodec{}
+}
+
+func (c *JsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (c *JsonCodec) Encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (c *JsonCodec) Decode(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
+
+// XmlCodec encodes and decodes application/xml bodies.
+// Values must be supported by encoding/xml package, maps are not supported.
+type XmlCodec struct{}
+
+// NewXmlCodec creates a new XML codec
+func NewXmlCodec() *XmlCodec {
+	return &XmlCodec{}
+}
+
+func (c *XmlCodec) ContentType() string {
+	return "application/xml"
+}
+
+func (c *XmlCodec) Encode(value interface{}) ([]byte, error) {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (c *XmlCodec) Decode(data []byte, target interface{}) error {
+	return xml.Unmarshal(data, target)
+}
+
+// FormCodec encodes and decodes application/x-www-form-urlencoded bodies.
+// Values are converted to and from flat maps using their JSON representation,
+// nested objects are written as JSON strings.
+type FormCodec struct{}
+
+// NewFormCodec creates a new form codec
+func NewFormCodec() *FormCodec {
+	return &FormCodec{}
+}
+
+func (c *FormCodec) ContentType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+func (c *FormCodec) Encode(value interface{}) ([]byte, error) {
+	if values, ok := value.(url.Values); ok {
+		return []byte(values.Encode()), nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("form codec supports only objects: %w", err)
+	}
+
+	values := url.Values{}
+	for key, field := range fields {
+		switch v := field.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			for _, item := range v {
+				values.Add(key, c.formatValue(item))
+			}
+		default:
+			values.Set(key, c.formatValue(v))
+		}
+	}
+	return []byte(values.Encode()), nil
+}
+
+func (c *FormCodec) formatValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	return cconv.StringConverter.ToString(value)
+}
+
+func (c *FormCodec) Decode(data []byte, target interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	if result, ok := target.(*url.Values); ok {
+		*result = values
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if len(value) == 1 {
+			fields[key] = value[0]
+		} else {
+			fields[key] = value
+		}
+	}
+	if result, ok := target.(*map[string]interface{}); ok {
+		*result = fields
+		return nil
+	}
+
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, target)
+}
+
+// MsgpackCodec encodes and decodes application/msgpack bodies.
+// Struct fields are mapped using their json tags.
+type MsgpackCodec struct{}
+
+// NewMsgpackCodec creates a new msgpack codec
+func NewMsgpackCodec() *MsgpackCodec {
+	return &MsgpackCodec{}
+}
+
+func (c *MsgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (c *MsgpackCodec) Encode(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *MsgpackCodec) Decode(data []byte, target interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(target)
+}
+
+// ProtobufCodec encodes and decodes application/x-protobuf bodies.
+// Values and targets must implement proto.Message.
+type ProtobufCodec struct{}
+
+// NewProtobufCodec creates a new protobuf codec
+func NewProtobufCodec() *ProtobufCodec {
+	return &ProtobufCodec{}
+}
+
+func (c *ProtobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (c *ProtobufCodec) Encode(value interface{}) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec can't encode %T, proto.Message is expected", value)
+	}
+	return proto.Marshal(message)
+}
+
+func (c *ProtobufCodec) Decode(data []byte, target interface{}) error {
+	message, ok := target.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec can't decode into %T, proto.Message is expected", target)
+	}
+	return proto.Unmarshal(data, message)
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+)
+
+const (
+	// BrotliEncoding is a name of brotli content encoding
+	BrotliEncoding = "br"
+	// GzipEncoding is a name of gzip content encoding
+	GzipEncoding = "gzip"
+	// DeflateEncoding is a name of deflate (zlib) content encoding
+	DeflateEncoding = "deflate"
+	// IdentityEncoding is a name of content encoding without compression
+	IdentityEncoding = "identity"
+
+	// AcceptEncodings is a value of Accept-Encoding header with all supported encodings
+	AcceptEncodings = "br, gzip, deflate"
+)
+
+/*
+HttpCompression compresses HTTP responses and decompresses request bodies.
+
+Responses are compressed with brotli, gzip or deflate encodings negotiated from
+Accept-Encoding request header, when their content type is in the allowlist and
+their size reaches the minimum. Request bodies sent with Content-Encoding header
+are transparently decompressed regardless of the enabled option.
+
+Configuration parameters:
+
+  - options:
+  - compression:
+  - enabled:                 turns on response compression (default: false)
+  - min_size:                minimum response size in bytes to compress (default: 1024)
+  - level:                   compression level from 1 (fastest) to 9 (best), -1 for default (default: -1)
+  - content_types:           comma-separated list of compressed content types, wildcards like text/* are allowed
+    (default: application/json,application/xml,application/javascript,text/*,image/svg+xml)
+
+Example:
+
+	endpoint := NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 8080,
+		"options.compression.enabled", true,
+		"options.compression.min_size", 512,
+	))
+*/
+type HttpCompression struct {
+	// Turns on response compression.
+	Enabled bool
+	// Minimum response size in bytes to compress.
+	MinSize int
+	// Compression level, -1 for default.
+	Level int
+	// Compressed content types.
+	ContentTypes []string
+}
+
+// NewHttpCompression creates a new instance of HTTP compression with default settings.
+// Returns: *HttpCompression
+func NewHttpCompression() *HttpCompression {
+	return &HttpCompression{
+		Enabled: false,
+		MinSize: 1024,
+		Level:   -1,
+		ContentTypes: []string{
+			"application/json",
+			"application/xml",
+			"application/javascript",
+			"text/*",
+			"image/svg+xml",
+		},
+	}
+}
+
+// Configure method are configures compression by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *HttpCompression) Configure(config *cconf.ConfigParams) {
+	config = config.GetSection("options.compression")
+
+	c.Enabled = config.GetAsBooleanWithDefault("enabled", c.Enabled)
+	c.MinSize = config.GetAsIntegerWithDefault("min_size", c.MinSize)
+	c.Level = config.GetAsIntegerWithDefault("level", c.Level)
+
+	contentTypes := config.GetAsNullableString("content_types")
+	if contentTypes != nil {
+		c.ContentTypes = make([]string, 0)
+		for _, contentType := range strings.Split(*contentTypes, ",") {
+			contentType = strings.ToLower(strings.TrimSpace(contentType))
+			if contentType != "" {
+				c.ContentTypes = append(c.ContentTypes, contentType)
+			}
+		}
+	}
+}
+
+// Handler method are wraps HTTP handler with request decompression and response compression.
+// Parameters:
+//   - next  http.Handler  a handler to wrap.
+//
+// Returns: http.Handler
+func (c *HttpCompression) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		if encoding != "" && r.Body != nil && r.Body != http.NoBody {
+			body, err := DecompressBody(encoding, r.Body)
+			if err != nil {
+				HttpResponseSender.SendError(w, r,
+					cerr.NewBadRequestError("", "INVALID_CONTENT_ENCODING", "Failed to decompress request body").
+						WithDetails("encoding", encoding).WithCause(err))
+				return
+			}
+			r.Body = body
+			r.ContentLength = -1
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+		}
+
+		if !c.Enabled || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding = NegotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writer := &compressResponseWriter{
+			ResponseWriter: w,
+			compression:    c,
+			encoding:       encoding,
+			status:         http.StatusOK,
+		}
+		defer writer.Close()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+func (c *HttpCompression) isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.ContentTypes {
+		if allowed == "*/*" || allowed == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *HttpCompression) newWriter(encoding string, w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	switch encoding {
+	case BrotliEncoding:
+		if level < 0 || level > brotli.BestCompression {
+			level = brotli.DefaultCompression
+		}
+		return brotli.NewWriterLevel(w, level), nil
+	case GzipEncoding:
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			level = flate.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case DeflateEncoding:
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			level = flate.DefaultCompression
+		}
+		return zlib.NewWriterLevel(w, level)
+	}
+	return nil, errors.New("unsupported content encoding " + encoding)
+}
+
+// NegotiateEncoding selects the best supported content encoding
+// for the given Accept-Encoding header value. Brotli is preferred over gzip and deflate
+// when their qualities are equal.
+// Parameters:
+//   - acceptEncoding  string  a value of Accept-Encoding header.
+//
+// Returns: string
+// selected encoding or empty string if response shall not be compressed.
+func NegotiateEncoding(acceptEncoding string) string {
+	type candidate struct {
+		encoding string
+		quality  float64
+		priority int
+	}
+	priorities := map[string]int{BrotliEncoding: 0, GzipEncoding: 1, DeflateEncoding: 2}
+
+	candidates := make([]candidate, 0)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		encoding := strings.ToLower(strings.TrimSpace(fields[0]))
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		if encoding == "*" {
+			encoding = GzipEncoding
+		}
+		if priority, ok := priorities[encoding]; ok {
+			candidates = append(candidates, candidate{encoding, quality, priority})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].quality != candidates[j].quality {
+			return candidates[i].quality > candidates[j].quality
+		}
+		return candidates[i].priority < candidates[j].priority
+	})
+	return candidates[0].encoding
+}
+
+// DecompressBody wraps a body compressed with the given content encoding
+// into a reader that returns decompressed data.
+// Deflate bodies are accepted both in zlib and raw formats.
+// Parameters:
+//   - encoding  string         a value of Content-Encoding header.
+//   - body      io.ReadCloser  a compressed body.
+//
+// Returns: io.ReadCloser, error
+// decompressed body or error when the encoding is not supported or data is corrupted.
+func DecompressBody(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", IdentityEncoding:
+		return body, nil
+	case BrotliEncoding:
+		return &decompressReader{Reader: brotli.NewReader(body), body: body}, nil
+	case GzipEncoding, "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressReader{Reader: reader, closer: reader, body: body}, nil
+	case DeflateEncoding:
+		buffered := bufio.NewReader(body)
+		header, _ := buffered.Peek(2)
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, err
+			}
+			return &decompressReader{Reader: reader, closer: reader, body: body}, nil
+		}
+		reader := flate.NewReader(buffered)
+		return &decompressReader{Reader: reader, closer: reader, body: body}, nil
+	}
+	return nil, errors.New("unsupported content encoding " + encoding)
+}
+
+type decompressReader struct {
+	io.Reader
+	closer io.Closer
+	body   io.Closer
+}
+
+func (c *decompressReader) Close() error {
+	if c.closer != nil {
+		c.closer.Close()
+	}
+	return c.body.Close()
+}
+
+// Buffers the beginning of the response to decide whether it shall be compressed
+type compressResponseWriter struct {
+	http.ResponseWriter
+	compression *HttpCompression
+	encoding    string
+	status      int
+	buffer      []byte
+	decided     bool
+	writer      io.WriteCloser
+}
+
+func (c *compressResponseWriter) WriteHeader(status int) {
+	if c.decided {
+		return
+	}
+	c.status = status
+	// Responses without body are sent right away
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified {
+		c.decide(false)
+	}
+}
+
+func (c *compressResponseWriter) Write(data []byte) (int, error) {
+	if !c.decided {
+		c.buffer = append(c.buffer, data...)
+		if len(c.buffer) < c.compression.MinSize {
+			return len(data), nil
+		}
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if c.writer != nil {
+		return c.writer.Write(data)
+	}
+	return c.ResponseWriter.Write(data)
+}
+
+func (c *compressResponseWriter) decide(large bool) error {
+	c.decided = true
+	header := c.ResponseWriter.Header()
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" && len(c.buffer) > 0 {
+		contentType = http.DetectContentType(c.buffer)
+		header.Set("Content-Type", contentType)
+	}
+
+	compress := large && header.Get("Content-Encoding") == "" &&
+		c.compression.isCompressible(contentType)
+	if compress {
+		writer, err := c.compression.newWriter(c.encoding, c.ResponseWriter)
+		if err != nil {
+			compress = false
+		} else {
+			c.writer = writer
+			header.Set("Content-Encoding", c.encoding)
+			header.Del("Content-Length")
+		}
+	}
+
+	c.ResponseWriter.WriteHeader(c.status)
+	if len(c.buffer) == 0 {
+		return nil
+	}
+	buffer := c.buffer
+	c.buffer = nil
+	var err error
+	if c.writer != nil {
+		_, err = c.writer.Write(buffer)
+	} else {
+		_, err = c.ResponseWriter.Write(buffer)
+	}
+	return err
+}
+
+// Flush sends buffered data to the client
+func (c *compressResponseWriter) Flush() {
+	if !c.decided {
+		c.decide(len(c.buffer) >= c.compression.MinSize)
+	}
+	if flusher, ok := c.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection, i.e. for protocol upgrades
+func (c *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	c.decided = true
+	return hijacker.Hijack()
+}
+
+// Close completes the response and releases compressor
+func (c *compressResponseWriter) Close() error {
+	if !c.decided {
+		c.decide(false)
+	}
+	if c.writer != nil {
+		err := c.writer.Close()
+		c.writer = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+)
+
+// ServerOverloadedErrorCode is a code of error returned when requests are shed under load
+const ServerOverloadedErrorCode = "SERVER_OVERLOADED"
+
+/*
+HttpConcurrencyLimiter limits the number of requests processed by HTTP endpoint at the same time.
+
+Requests over the limit wait in a bounded queue up to the queue timeout.
+When the queue is full, the wait times out or adaptive shedding detects that queued
+requests wait longer than the target delay, requests are rejected early with 503 error.
+
+Configuration parameters:
+
+  - options:
+    - concurrency:
+      - max_in_flight:       maximum number of requests processed at the same time, 0 for no limit (default: 0)
+      - queue_size:          maximum number of requests waiting for processing (default: 0)
+      - queue_timeout:       maximum time a request waits in the queue in milliseconds (default: 1 sec)
+      - adaptive_shedding:   reject requests without queuing while queue delays exceed the target (default: false)
+      - target_delay:        target queue delay for adaptive shedding in milliseconds (default: 100)
+
+Counters:
+
+  - http_endpoint.in_flight        number of requests in processing
+  - http_endpoint.queue_length     number of requests waiting in the queue
+  - http_endpoint.queue_time       time requests waited in the queue
+  - http_endpoint.shed_requests    number of requests rejected with 503 error
+*/
+type HttpConcurrencyLimiter struct {
+	// Maximum number of requests processed at the same time.
+	MaxInFlight int
+	// Maximum number of requests waiting for processing.
+	QueueSize int
+	// Maximum time a request waits in the queue.
+	QueueTimeout time.Duration
+	// Reject requests without queuing while queue delays exceed the target.
+	AdaptiveShedding bool
+	// Target queue delay for adaptive shedding.
+	TargetDelay time.Duration
+
+	counters *ccount.CompositeCounters
+	lock     sync.Mutex
+	global   *inFlightLimit
+}
+
+// Semaphore with a bounded queue shared by requests of the same scope
+type inFlightLimit struct {
+	slots      chan struct{}
+	queued     int32
+	queueDelay int64 // moving average in nanoseconds
+}
+
+// Queue settings taken under the lock for a single request
+type queueSettings struct {
+	queueSize        int
+	queueTimeout     time.Duration
+	adaptiveShedding bool
+	targetDelay      time.Duration
+}
+
+// NewHttpConcurrencyLimiter creates a new instance of the concurrency limiter.
+// Parameters:
+//   - counters  *ccount.CompositeCounters  (optional) counters to report load.
+//
+// Returns: *HttpConcurrencyLimiter
+func NewHttpConcurrencyLimiter(counters *ccount.CompositeCounters) *HttpConcurrencyLimiter {
+	if counters == nil {
+		counters = ccount.NewCompositeCounters()
+	}
+	return &HttpConcurrencyLimiter{
+		MaxInFlight:      0,
+		QueueSize:        0,
+		QueueTimeout:     time.Second,
+		AdaptiveShedding: false,
+		TargetDelay:      100 * time.Millisecond,
+		counters:         counters,
+	}
+}
+
+// Configure method are configures the limiter by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *HttpConcurrencyLimiter) Configure(config *cconf.ConfigParams) {
+	config = config.GetSection("options.concurrency")
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.MaxInFlight = config.GetAsIntegerWithDefault("max_in_flight", c.MaxInFlight)
+	c.QueueSize = config.GetAsIntegerWithDefault("queue_size", c.QueueSize)
+	c.QueueTimeout = time.Duration(config.GetAsLongWithDefault("queue_timeout",
+		int64(c.QueueTimeout/time.Millisecond))) * time.Millisecond
+	c.AdaptiveShedding = config.GetAsBooleanWithDefault("adaptive_shedding", c.AdaptiveShedding)
+	c.TargetDelay = time.Duration(config.GetAsLongWithDefault("target_delay",
+		int64(c.TargetDelay/time.Millisecond))) * time.Millisecond
+	c.global = nil
+}
+
+// Handler method are wraps HTTP handler with the global in-flight limit.
+// Parameters:
+//   - next  http.Handler  a handler to wrap.
+//
+// Returns: http.Handler
+func (c *HttpConcurrencyLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.lock.Lock()
+		if c.global == nil && c.MaxInFlight > 0 {
+			c.global = newInFlightLimit(c.MaxInFlight)
+		}
+		limit := c.global
+		settings := c.queueSettings()
+		c.lock.Unlock()
+
+		if limit == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		c.limit(w, r, limit, settings, next.ServeHTTP)
+	})
+}
+
+// LimitRoute method returns an interceptor with a separate in-flight limit for a route.
+// Queue settings are shared with the global limit.
+// Parameters:
+//   - maxInFlight  int  maximum number of requests to the route processed at the same time.
+//
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *HttpConcurrencyLimiter) LimitRoute(maxInFlight int) func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	limit := newInFlightLimit(maxInFlight)
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		c.lock.Lock()
+		settings := c.queueSettings()
+		c.lock.Unlock()
+
+		c.limit(res, req, limit, settings, next)
+	}
+}
+
+// Must be called under the lock
+func (c *HttpConcurrencyLimiter) queueSettings() queueSettings {
+	return queueSettings{
+		queueSize:        c.QueueSize,
+		queueTimeout:     c.QueueTimeout,
+		adaptiveShedding: c.AdaptiveShedding,
+		targetDelay:      c.TargetDelay,
+	}
+}
+
+func newInFlightLimit(maxInFlight int) *inFlightLimit {
+	return &inFlightLimit{slots: make(chan struct{}, maxInFlight)}
+}
+
+func (c *HttpConcurrencyLimiter) limit(res http.ResponseWriter, req *http.Request,
+	limit *inFlightLimit, settings queueSettings, next http.HandlerFunc) {
+
+	reason := c.acquire(req, limit, settings)
+	if reason != "" {
+		c.counters.IncrementOne("http_endpoint.shed_requests")
+		if req.Context().Err() != nil {
+			return
+		}
+		correlationId := req.URL.Query().Get("correlation_id")
+		if correlationId == "" {
+			correlationId = req.Header.Get("correlation_id")
+		}
+		res.Header().Set("Retry-After", strconv.Itoa(1))
+		HttpResponseSender.SendError(res, req,
+			cerr.NewUnknownError(correlationId, ServerOverloadedErrorCode, "Server is overloaded, try again later").
+				WithDetails("reason", reason).
+				WithStatus(http.StatusServiceUnavailable))
+		return
+	}
+
+	c.counters.Last("http_endpoint.in_flight", float32(len(limit.slots)))
+	defer func() {
+		<-limit.slots
+		c.counters.Last("http_endpoint.in_flight", float32(len(limit.slots)))
+	}()
+	next(res, req)
+}
+
+// Takes a processing slot and returns a reason when the request shall be rejected
+func (c *HttpConcurrencyLimiter) acquire(req *http.Request, limit *inFlightLimit, settings queueSettings) string {
+	select {
+	case limit.slots <- struct{}{}:
+		// Requests without waiting let adaptive shedding recover
+		c.updateQueueDelay(limit, 0)
+		return ""
+	default:
+	}
+
+	if settings.queueSize <= 0 {
+		return "max_in_flight"
+	}
+	if settings.adaptiveShedding && time.Duration(atomic.LoadInt64(&limit.queueDelay)) > settings.targetDelay {
+		return "queue_delay"
+	}
+	queued := atomic.AddInt32(&limit.queued, 1)
+	defer func() {
+		c.counters.Last("http_endpoint.queue_length", float32(atomic.AddInt32(&limit.queued, -1)))
+	}()
+	if int(queued) > settings.queueSize {
+		return "queue_full"
+	}
+	c.counters.Last("http_endpoint.queue_length", float32(queued))
+
+	start := time.Now()
+	timer := time.NewTimer(settings.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case limit.slots <- struct{}{}:
+		delay := time.Since(start)
+		c.recordQueueDelay(limit, delay)
+		return ""
+	case <-timer.C:
+		c.recordQueueDelay(limit, settings.queueTimeout)
+		return "queue_timeout"
+	case <-req.Context().Done():
+		return "cancelled"
+	}
+}
+
+func (c *HttpConcurrencyLimiter) recordQueueDelay(limit *inFlightLimit, delay time.Duration) {
+	c.counters.Stats("http_endpoint.queue_time", float32(delay.Milliseconds()))
+	c.updateQueueDelay(limit, delay)
+}
+
+// Updates moving average of queue delays used by adaptive shedding
+func (c *HttpConcurrencyLimiter) updateQueueDelay(limit *inFlightLimit, delay time.Duration) {
+	for {
+		average := atomic.LoadInt64(&limit.queueDelay)
+		if average == 0 && delay == 0 {
+			return
+		}
+		updated := average + (int64(delay)-average)/4
+		if atomic.CompareAndSwapInt64(&limit.queueDelay, average, updated) {
+			return
+		}
+	}
+}
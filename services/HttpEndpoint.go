@@ -5,20 +5,24 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/json"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
 	cvalid "github.com/pip-services3-go/pip-services3-commons-go/validate"
+	ccon "github.com/pip-services3-go/pip-services3-components-go/connect"
 	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
 	clog "github.com/pip-services3-go/pip-services3-components-go/log"
 	"github.com/pip-services3-go/pip-services3-rpc-go/connect"
@@ -37,7 +41,7 @@ Parameters to pass to the configure method for component configuration:
   - "connection.discovery_key" - the key to use for connection resolving in a discovery service;
   - "connection.protocol" - the connection"s protocol;
   - "connection.host" - the target host;
-  - "connection.port" - the target port;
+  - "connection.port" - the target port, 0 to use a port assigned by the system;
   - "connection.uri" - the target URI.
   - credential - the HTTPS credentials:
   - "credential.ssl_key_file" - the SSL func (c *HttpEndpoint )key in PEM
@@ -46,14 +50,54 @@ Parameters to pass to the configure method for component configuration:
   - options - the http endpoint options
   - "options.client_auth_type" - authentification type (request_client_cert, require_any_client_cert, verify_client_cert_if_given, require_and_verify_client_cert, default: no_client_auth)
   - "options.certificate_server_name" - certificates server (default: localhost)
+  - "options.request_max_size" - maximum size of request body in bytes, -1 to disable the limit (default: 1 MB)
+  - "options.file_max_size" - maximum size of multipart request body in bytes, -1 to disable the limit (default: 200 MB)
+  - "options.maintenance_enabled" - starts the endpoint in maintenance mode (default: false)
+  - "options.maintenance_retry_after" - value of Retry-After header in maintenance mode in seconds, 0 to skip the header (default: 3600)
+  - "options.maintenance_allowed_routes" - comma-separated list of full routes available in maintenance mode, including base routes of services, i.e. "api/v1/status" (default: heartbeat,status)
+  - "options.maintenance_route" - (optional) route to get and change maintenance mode at runtime, i.e. "maintenance", registered only when an authorizer is set with SetMaintenanceAuthorizer
+  - "options.rate_limit.enabled" - turns on rate limiting of all routes per client (default: false)
+  - "options.rate_limit.algorithm" - token_bucket or sliding_window (default: token_bucket)
+  - "options.rate_limit.limit" - maximum number of requests per client within the window (default: 100)
+  - "options.rate_limit.window" - rate limit window in milliseconds (default: 60 sec)
+  - "options.rate_limit.key" - client key: ip, user or header:<name>, user is not known to the endpoint-wide limit that runs before authentication (default: ip)
+  - "options.rate_limit.trusted_proxies" - comma-separated addresses or CIDR networks of proxies trusted to set X-Forwarded-For header (default: none)
+  - "options.concurrency.max_in_flight" - maximum number of requests processed at the same time, 0 for no limit (default: 0)
+  - "options.concurrency.queue_size" - maximum number of requests waiting for processing (default: 0)
+  - "options.concurrency.queue_timeout" - maximum time a request waits in the queue in milliseconds (default: 1 sec)
+  - "options.concurrency.adaptive_shedding" - reject requests early while queue delays exceed the target (default: false)
+  - "options.concurrency.target_delay" - target queue delay for adaptive shedding in milliseconds (default: 100)
+  - "options.connect_timeout" - time to receive request headers in milliseconds, used when read_header_timeout is not set (default: 60 sec)
+  - "options.read_header_timeout" - time to read request headers in milliseconds (default: connect_timeout)
+  - "options.read_timeout" - time to read the entire request including body in milliseconds, 0 for no limit (default: 0)
+  - "options.write_timeout" - time to write the response in milliseconds, 0 for no limit (default: 0)
+  - "options.idle_timeout" - time to keep idle keep-alive connections in milliseconds (default: 120 sec)
+  - "options.max_header_bytes" - maximum size of request headers in bytes (default: 1 MB)
+  - "options.request_timeout" - maximum time to process a request in milliseconds, 0 for no limit;
+    on timeout the request context is cancelled and 504 error is returned (default: 0)
+  - "options.access_log.enabled" - turns on logging of served requests (default: false)
+  - "options.access_log.format" - json, common or combined (default: json)
+  - "options.access_log.sample_rate" - part of requests to log from 0 to 1, failed requests are always logged (default: 1)
+  - "options.access_log.exclude_routes" - comma-separated list of routes not to log (default: heartbeat)
+  - "options.metrics.enabled" - turns on recording of per-route request metrics into counters (default: true)
+  - "options.drain_timeout" - time to wait for active requests to complete on close in milliseconds (default: 5 sec)
+  - "options.pre_stop_delay" - time between failing readiness and closing the server in milliseconds (default: 0)
+  - "options.compression.enabled" - turns on response compression with br, gzip or deflate (default: false)
+  - "options.compression.min_size" - minimum response size in bytes to compress (default: 1024)
+  - "options.compression.level" - compression level from 1 to 9, -1 for default (default: -1)
+  - "options.compression.content_types" - comma-separated list of compressed content types (default: application/json,application/xml,application/javascript,text/*,image/svg+xml)
     References:
 
+The endpoint continues distributed traces of incoming requests from W3C Trace Context
+traceparent and tracestate headers, the server span is available via SpanFromRequest.
+
 A logger, counters, and a connection resolver can be referenced by passing the
 following references to the object"s setReferences method:
 
   - logger: "*:logger:*:*:1.0";
   - counters: "*:counters:*:*:1.0";
-  - discovery: "*:discovery:*:*:1.0" (for the connection resolver).
+  - discovery: "*:discovery:*:*:1.0" (for the connection resolver);
+  - rate limit store: "*:rate-limit-store:*:*:1.0" (optional IRateLimitStore, in-memory by default).
 
 Examples:
 
@@ -66,19 +110,42 @@ Examples:
 type HttpEndpoint struct {
 	ITlsConfigurator
 
-	defaultConfig          *cconf.ConfigParams
-	server                 *http.Server
-	router                 *mux.Router
-	connectionResolver     *connect.HttpConnectionResolver
-	logger                 *clog.CompositeLogger
-	counters               *ccount.CompositeCounters
-	maintenanceEnabled     bool
-	fileMaxSize            int64
-	protocolUpgradeEnabled bool
-	uri                    string
-	registrations          []IRegisterable
-	allowedHeaders         []string
-	allowedOrigins         []string
+	defaultConfig            *cconf.ConfigParams
+	server                   *http.Server
+	router                   *mux.Router
+	connectionResolver       *connect.HttpConnectionResolver
+	logger                   *clog.CompositeLogger
+	counters                 *ccount.CompositeCounters
+	maintenanceLock          sync.RWMutex
+	maintenanceEnabled       bool
+	maintenanceReason        string
+	maintenanceRetryAfter    int
+	maintenanceAllowedRoutes []string
+	maintenanceRoute         string
+	maintenanceAuthorizer    func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)
+	requestMaxSize           int64
+	fileMaxSize              int64
+	protocolUpgradeEnabled   bool
+	uri                      string
+	registrations            []IRegisterable
+	allowedHeaders           []string
+	allowedOrigins           []string
+	compression              *HttpCompression
+	rateLimiter              *HttpRateLimiter
+	concurrencyLimiter       *HttpConcurrencyLimiter
+	accessLog                *HttpAccessLog
+	metrics                  *httpMetrics
+	readHeaderTimeout        time.Duration
+	readTimeout              time.Duration
+	writeTimeout             time.Duration
+	idleTimeout              time.Duration
+	maxHeaderBytes           int
+	requestTimeout           time.Duration
+	drainTimeout             time.Duration
+	preStopDelay             time.Duration
+	ready                    int32
+	activeRequests           *activeRequests
+	listener                 net.Listener
 
 	clientAuthType        string
 	certificateServerName string
@@ -107,9 +174,13 @@ func NewHttpEndpoint() *HttpEndpoint {
 		"options.debug", "true",
 	)
 	c.connectionResolver = connect.NewHttpConnectionResolver()
+	c.connectionResolver.AllowZeroPort = true
 	c.logger = clog.NewCompositeLogger()
 	c.counters = ccount.NewCompositeCounters()
 	c.maintenanceEnabled = false
+	c.maintenanceRetryAfter = 3600
+	c.maintenanceAllowedRoutes = []string{"heartbeat", "status"}
+	c.requestMaxSize = 1024 * 1024
 	c.fileMaxSize = 200 * 1024 * 1024
 	c.protocolUpgradeEnabled = false
 	c.registrations = make([]IRegisterable, 0, 0)
@@ -121,9 +192,21 @@ func NewHttpEndpoint() *HttpEndpoint {
 		//"X-CSRF-Token",
 		//"Authorization",
 		"correlation_id",
+		TraceParentHeader,
+		TraceStateHeader,
 		//"access_token",
 	}
 	c.allowedOrigins = make([]string, 0)
+	c.compression = NewHttpCompression()
+	c.rateLimiter = NewHttpRateLimiter(c.logger, c.counters)
+	c.concurrencyLimiter = NewHttpConcurrencyLimiter(c.counters)
+	c.accessLog = NewHttpAccessLog(c.logger)
+	c.metrics = newHttpMetrics(c.counters)
+	c.readHeaderTimeout = 60 * time.Second
+	c.idleTimeout = 120 * time.Second
+	c.maxHeaderBytes = http.DefaultMaxHeaderBytes
+	c.drainTimeout = 5 * time.Second
+	c.activeRequests = newActiveRequests()
 
 	c.ITlsConfigurator = &c
 	return &c
@@ -153,10 +236,41 @@ func (c *HttpEndpoint) Configure(config *cconf.ConfigParams) {
 	c.connectionResolver.Configure(config)
 
 	c.maintenanceEnabled = config.GetAsBooleanWithDefault("options.maintenance_enabled", c.maintenanceEnabled)
+	c.maintenanceRetryAfter = config.GetAsIntegerWithDefault("options.maintenance_retry_after", c.maintenanceRetryAfter)
+	c.maintenanceRoute = config.GetAsStringWithDefault("options.maintenance_route", c.maintenanceRoute)
+	if routes := config.GetAsNullableString("options.maintenance_allowed_routes"); routes != nil {
+		c.maintenanceAllowedRoutes = make([]string, 0)
+		for _, route := range strings.Split(*routes, ",") {
+			route = strings.Trim(strings.TrimSpace(route), "/")
+			if route != "" {
+				c.maintenanceAllowedRoutes = append(c.maintenanceAllowedRoutes, route)
+			}
+		}
+	}
+	c.requestMaxSize = config.GetAsLongWithDefault("options.request_max_size", c.requestMaxSize)
 	c.fileMaxSize = config.GetAsLongWithDefault("options.file_max_size", c.fileMaxSize)
 	c.protocolUpgradeEnabled = config.GetAsBooleanWithDefault("options.protocol_upgrade_enabled", c.protocolUpgradeEnabled)
 	c.clientAuthType = config.GetAsStringWithDefault("options.client_auth_type", c.clientAuthType)
 	c.certificateServerName = config.GetAsStringWithDefault("options.certificate_server_name", c.certificateServerName)
+	c.compression.Configure(config)
+	c.rateLimiter.Configure(config)
+	c.concurrencyLimiter.Configure(config)
+	c.accessLog.Configure(config)
+	c.metrics.configure(config)
+	millis := func(key string, defaultValue time.Duration) time.Duration {
+		return time.Duration(config.GetAsLongWithDefault(key, int64(defaultValue/time.Millisecond))) * time.Millisecond
+	}
+	c.readHeaderTimeout = millis("options.connect_timeout", c.readHeaderTimeout)
+	c.readHeaderTimeout = millis("options.read_header_timeout", c.readHeaderTimeout)
+	c.readTimeout = millis("options.read_timeout", c.readTimeout)
+	c.writeTimeout = millis("options.write_timeout", c.writeTimeout)
+	c.idleTimeout = millis("options.idle_timeout", c.idleTimeout)
+	c.maxHeaderBytes = config.GetAsIntegerWithDefault("options.max_header_bytes", c.maxHeaderBytes)
+	c.requestTimeout = millis("options.request_timeout", c.requestTimeout)
+	c.drainTimeout = time.Duration(config.GetAsLongWithDefault("options.drain_timeout",
+		int64(c.drainTimeout/time.Millisecond))) * time.Millisecond
+	c.preStopDelay = time.Duration(config.GetAsLongWithDefault("options.pre_stop_delay",
+		int64(c.preStopDelay/time.Millisecond))) * time.Millisecond
 
 	headers := strings.Split(config.GetAsStringWithDefault("cors_headers", ""), ",")
 	if headers != nil && len(headers) > 0 {
@@ -187,6 +301,7 @@ func (c *HttpEndpoint) SetReferences(references crefer.IReferences) {
 	c.logger.SetReferences(references)
 	c.counters.SetReferences(references)
 	c.connectionResolver.SetReferences(references)
+	c.rateLimiter.SetReferences(references)
 }
 
 // IsOpen method is  whether or not this endpoint is open with an actively listening REST server.
@@ -194,6 +309,17 @@ func (c *HttpEndpoint) IsOpen() bool {
 	return c.server != nil
 }
 
+// IsReady method checks if this endpoint is open and accepts requests.
+// The endpoint stops being ready as soon as it starts closing.
+func (c *HttpEndpoint) IsReady() bool {
+	return atomic.LoadInt32(&c.ready) == 1
+}
+
+// ActiveRequests method returns the number of requests in progress.
+func (c *HttpEndpoint) ActiveRequests() int {
+	return c.activeRequests.count()
+}
+
 // Opens a connection using the parameters resolved by the referenced connection
 // resolver and creates a REST server (service) using the set options and parameters.
 // Parameters:
@@ -210,9 +336,15 @@ func (c *HttpEndpoint) Open(correlationId string) error {
 		return err
 	}
 
-	c.uri = connection.Uri()
 	url := connection.Host() + ":" + strconv.Itoa(connection.Port())
-	c.server = &http.Server{Addr: url}
+	server := &http.Server{
+		Addr:              url,
+		ReadHeaderTimeout: c.readHeaderTimeout,
+		ReadTimeout:       c.readTimeout,
+		WriteTimeout:      c.writeTimeout,
+		IdleTimeout:       c.idleTimeout,
+		MaxHeaderBytes:    c.maxHeaderBytes,
+	}
 	c.router = mux.NewRouter()
 
 	// Add default origins
@@ -230,17 +362,31 @@ func (c *HttpEndpoint) Open(correlationId string) error {
 		"PATCH",
 	})
 	allowedHeaders := handlers.AllowedHeaders(c.allowedHeaders)
-	c.server.Handler = handlers.CORS(allowedOrigins, allowedMethods, allowedHeaders)(c.router)
+	server.Handler = c.trackRequests(c.traceRequests(c.recordRequests(c.concurrencyLimiter.Handler(
+		handlers.CORS(allowedOrigins, allowedMethods, allowedHeaders)(c.router)))))
 
+	c.router.Use(c.captureRoute)
+
+	c.router.Use(c.compression.Handler)
 	c.router.Use(c.noCache)
 	c.router.Use(c.doMaintenance)
+	c.router.Use(c.rateLimiter.Handler)
+	if c.rateLimiter.Enabled && strings.ToLower(c.rateLimiter.Key) == "user" && c.rateLimiter.KeyFunc == nil {
+		c.logger.Warn(correlationId, "Rate limit by user is checked before authentication and falls back to client address")
+	}
 
+	if c.maintenanceRoute != "" {
+		if c.maintenanceAuthorizer != nil {
+			c.RegisterRouteWithAuth("get", c.maintenanceRoute, nil, c.maintenanceAuthorizer, c.maintenance)
+			c.RegisterRouteWithAuth("post", c.maintenanceRoute, nil, c.maintenanceAuthorizer, c.maintenance)
+		} else {
+			c.logger.Warn(correlationId, "Maintenance route %s is not registered because maintenance authorizer is not set", c.maintenanceRoute)
+		}
+	}
 	c.performRegistrations()
 
-	chErr := make(chan error, 1)
-	defer close(chErr)
-
-	if connection.Protocol() == "https" {
+	https := connection.Protocol() == "https"
+	if https {
 		clientAuthType := c.ITlsConfigurator.GetClientAuthType()
 
 		certificates, err := c.ITlsConfigurator.GetCertificates()
@@ -248,7 +394,7 @@ func (c *HttpEndpoint) Open(correlationId string) error {
 			return err
 		}
 
-		c.server.TLSConfig = &tls.Config{
+		server.TLSConfig = &tls.Config{
 			// TLS versions below 1.2 are considered insecure
 			// see https://www.rfc-editor.org/rfc/rfc7525.txt for details
 			MinVersion:   tls.VersionTLS12,
@@ -263,42 +409,46 @@ func (c *HttpEndpoint) Open(correlationId string) error {
 		}
 
 		if caCertPool != nil {
-			c.server.TLSConfig.ClientCAs = caCertPool
+			server.TLSConfig.ClientCAs = caCertPool
 		}
-
-		go func() {
-			servErr := c.server.ListenAndServeTLS("", "")
-			if servErr != nil {
-				select {
-				default:
-					chErr <- err
-				case <-chErr:
-				}
-			}
-		}()
-
-	} else {
-		go func() {
-			servErr := c.server.ListenAndServe()
-			if servErr != nil {
-				select {
-				default:
-					chErr <- err
-				case <-chErr:
-				}
-			}
-		}()
 	}
 
-	// waiting 1 second for start up services
-	select {
-	case <-time.After(time.Second):
-	case err := <-chErr:
-		c.logger.Error(correlationId, err, "ERROR_STARTUP_SERVICE", "Can't start REST service at %s", c.uri)
+	// Bind synchronously to report port conflicts right away
+	listener, err := net.Listen("tcp", url)
+	if err != nil {
+		err = cerr.NewConnectionError(correlationId, "CANNOT_BIND", "Can't bind REST service to "+url).
+			WithDetails("address", url).WithCause(err)
+		c.logger.Error(correlationId, err, "ERROR_STARTUP_SERVICE", "Can't start REST service at %s", connection.Uri())
 		return err
 	}
 
-	regErr := c.connectionResolver.Register(correlationId)
+	// Report port assigned by the system when port 0 is configured.
+	// The resolved connection is copied to bind port 0 again after reopening.
+	if port := listener.Addr().(*net.TCPAddr).Port; port != connection.Port() {
+		connection = ccon.NewConnectionParams(connection.Value())
+		connection.SetPort(port)
+		connection.SetUri(connection.Protocol() + "://" + connection.Host() + ":" + strconv.Itoa(port))
+	}
+
+	c.server = server
+	c.listener = listener
+	c.uri = connection.Uri()
+
+	go func() {
+		var servErr error
+		if https {
+			servErr = server.ServeTLS(listener, "", "")
+		} else {
+			servErr = server.Serve(listener)
+		}
+		if servErr != nil && servErr != http.ErrServerClosed {
+			c.logger.Error(correlationId, servErr, "ERROR_SERVE", "REST service at %s failed", connection.Uri())
+		}
+	}()
+
+	atomic.StoreInt32(&c.ready, 1)
+
+	regErr := c.connectionResolver.RegisterConnection(correlationId, connection)
 	if regErr != nil {
 		c.logger.Error(correlationId, regErr, "ERROR_REG_SRV", "Can't register REST service at %s", c.uri)
 	}
@@ -306,6 +456,34 @@ func (c *HttpEndpoint) Open(correlationId string) error {
 	return regErr
 }
 
+// Addr method returns the network address the endpoint listens on, i.e. "127.0.0.1:3000".
+// When port 0 is configured the address contains the port assigned by the system.
+// Returns empty string if the endpoint is not open.
+func (c *HttpEndpoint) Addr() string {
+	if c.listener == nil {
+		return ""
+	}
+	return c.listener.Addr().String()
+}
+
+// Metrics method returns metrics of requests served by the endpoint per route template.
+// Returns []HttpRouteMetrics sorted by route and method.
+func (c *HttpEndpoint) Metrics() []HttpRouteMetrics {
+	return c.metrics.snapshot()
+}
+
+// Tracks requests in progress to drain them on close
+func (c *HttpEndpoint) trackRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count, done := c.activeRequests.track(r, c.GetCorrelationId(r))
+		c.counters.Last("http_endpoint.active_requests", float32(count))
+		defer func() {
+			c.counters.Last("http_endpoint.active_requests", float32(done()))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Prevents IE from caching REST requests
 func (c *HttpEndpoint) noCache(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -316,22 +494,10 @@ func (c *HttpEndpoint) noCache(next http.Handler) http.Handler {
 	})
 }
 
-// Returns maintenance error code
-func (c *HttpEndpoint) doMaintenance(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Make this more sophisticated
-		if c.maintenanceEnabled {
-			w.Header().Add("Retry-After", "3600")
-			jsonStr, _ := json.Marshal(503)
-			w.Write(jsonStr)
-			next.ServeHTTP(w, r)
-		} else {
-			next.ServeHTTP(w, r)
-		}
-	})
-}
-
 // Close method are closes this endpoint and the REST server (service) that was opened earlier.
+// Before closing the endpoint stops being ready, unregisters from discovery services and waits
+// for pre_stop_delay. Then it stops accepting new connections and waits up to drain_timeout
+// for active requests to complete. Requests still running after that are cut off.
 // Parameters:
 //   - correlationId  string   (optional) transaction id to trace execution through call chain.
 //
@@ -339,17 +505,41 @@ func (c *HttpEndpoint) doMaintenance(next http.Handler) http.Handler {
 // an error if one is raised.
 func (c *HttpEndpoint) Close(correlationId string) error {
 	if c.server != nil {
+		// Fail readiness checks and leave discovery before shutting down
+		atomic.StoreInt32(&c.ready, 0)
+		unregErr := c.connectionResolver.Unregister(correlationId)
+		if unregErr != nil {
+			c.logger.Warn(correlationId, "Failed to unregister REST service at %s: %s", c.uri, unregErr.Error())
+		}
+		if c.preStopDelay > 0 {
+			c.logger.Debug(correlationId, "Waiting %d ms before closing REST service at %s", c.preStopDelay.Milliseconds(), c.uri)
+			time.Sleep(c.preStopDelay)
+		}
+
 		// Attempt a graceful shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), c.drainTimeout)
 		defer cancel()
 		clErr := c.server.Shutdown(ctx)
 		if clErr != nil {
-			c.logger.Warn(correlationId, "Failed while closing REST service: %s", clErr.Error())
-			return clErr
+			for _, request := range c.activeRequests.list() {
+				c.logger.Warn(request.correlationId, "Request %s %s was cut off after %d ms while closing REST service at %s",
+					request.method, request.path, time.Since(request.started).Milliseconds(), c.uri)
+				c.counters.IncrementOne("http_endpoint.cut_off_requests")
+			}
+			clErr = c.server.Close()
+			if clErr != nil {
+				c.logger.Warn(correlationId, "Failed while closing REST service: %s", clErr.Error())
+			}
+		}
+		// Release the port even when the server has not started serving yet
+		c.listener.Close()
+		if clErr == nil {
+			c.logger.Debug(correlationId, "Closed REST service at %s", c.uri)
 		}
-		c.logger.Debug(correlationId, "Closed REST service at %s", c.uri)
 		c.server = nil
+		c.listener = nil
 		c.uri = ""
+		return clErr
 	}
 	return nil
 }
@@ -418,13 +608,84 @@ func (c *HttpEndpoint) GetCorrelationId(req *http.Request) string {
 //   - action   http.HandlerFunc     the action to perform at the given route.
 func (c *HttpEndpoint) RegisterRoute(method string, route string, schema *cvalid.Schema,
 	action http.HandlerFunc) {
+	c.RegisterRouteWithOptions(method, route, schema, nil, action)
+}
+
+// RegisterRouteWithOptions method are registers an action in this objects REST server (service)
+// by the given method and route with settings that override endpoint defaults.
+// Request bodies larger than allowed by request_max_size or file_max_size (for multipart requests)
+// are rejected with 413 error.
+//   - method   string     the HTTP method of the route.
+//   - route    string     the route to register in this object"s REST server (service).
+//   - schema   *cvalid.Schema     the schema to use for parameter validation.
+//   - options  *RouteOptions     (optional) route settings.
+//   - action   http.HandlerFunc     the action to perform at the given route.
+func (c *HttpEndpoint) RegisterRouteWithOptions(method string, route string, schema *cvalid.Schema,
+	options *RouteOptions, action http.HandlerFunc) {
+	c.RegisterRouteWithAuthAndOptions(method, route, schema, options, nil, action)
+}
+
+// RegisterRouteWithAuthAndOptions method are registers an action with authorization in this objects REST server (service)
+// by the given method and route with settings that override endpoint defaults.
+// Route rate and concurrency limits are applied after the authorization, so rate limits
+// with "user" key can identify authenticated users.
+//   - method     string     the HTTP method of the route.
+//   - route      string     the route to register in this object"s REST server (service).
+//   - schema     *cvalid.Schema     the schema to use for parameter validation.
+//   - options    *RouteOptions     (optional) route settings.
+//   - authorize  (optional) the authorization interceptor.
+//   - action     http.HandlerFunc     the action to perform at the given route.
+func (c *HttpEndpoint) RegisterRouteWithAuthAndOptions(method string, route string, schema *cvalid.Schema,
+	options *RouteOptions, authorize func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc),
+	action http.HandlerFunc) {
 
 	method = strings.ToLower(method)
 	if method == "del" {
 		method = "delete"
 	}
 	route = c.fixRoute(route)
+	if options == nil {
+		options = &RouteOptions{}
+	}
+	requestMaxSize := options.RequestMaxSize
+	if requestMaxSize == 0 {
+		requestMaxSize = c.requestMaxSize
+	}
+	fileMaxSize := options.FileMaxSize
+	if fileMaxSize == 0 {
+		fileMaxSize = c.fileMaxSize
+	}
+	if options.MaxInFlight > 0 {
+		limitedAction := action
+		concurrencyLimit := c.concurrencyLimiter.LimitRoute(options.MaxInFlight)
+		action = func(w http.ResponseWriter, r *http.Request) {
+			concurrencyLimit(w, r, limitedAction)
+		}
+	}
+	if options.RateLimit > 0 {
+		limitedAction := action
+		rateLimit := c.rateLimiter.LimitRoute(strings.ToUpper(method)+" "+route, options.RateLimit, options.RateLimitWindow)
+		action = func(w http.ResponseWriter, r *http.Request) {
+			rateLimit(w, r, limitedAction)
+		}
+	}
+	if authorize != nil {
+		authorizedAction := action
+		action = func(w http.ResponseWriter, r *http.Request) {
+			authorize(w, r, authorizedAction)
+		}
+	}
+
 	actionCurl := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Limit size of request body
+		maxSize := requestMaxSize
+		if isMultipartRequest(r) {
+			maxSize = fileMaxSize
+		}
+		if !limitRequestBody(w, r, c.GetCorrelationId(r), maxSize) {
+			return
+		}
+
 		//  Perform validation
 		if schema != nil {
 			var params map[string]interface{} = make(map[string]interface{}, 0)
@@ -446,7 +707,7 @@ func (c *HttpEndpoint) RegisterRoute(method string, route string, schema *cvalid
 			r.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBuf))
 			//-------------------------
 			var body interface{}
-			json.Unmarshal(bodyBuf, &body)
+			HttpCodecs.ForRequest(r).Decode(bodyBuf, &body)
 			params["body"] = body
 
 			correlationId := c.GetCorrelationId(r)
@@ -458,7 +719,15 @@ func (c *HttpEndpoint) RegisterRoute(method string, route string, schema *cvalid
 		}
 		action(w, r)
 	})
-	c.router.Handle(route, actionCurl).Methods(strings.ToUpper(method))
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = c.requestTimeout
+	}
+	var handler http.Handler = actionCurl
+	if timeout > 0 {
+		handler = timeoutHandler(actionCurl, timeout, c.GetCorrelationId)
+	}
+	c.router.Handle(route, handler).Methods(strings.ToUpper(method))
 }
 
 // RegisterRouteWithAuth method are registers an action with authorization in this objects REST server (service)
@@ -472,15 +741,7 @@ func (c *HttpEndpoint) RegisterRoute(method string, route string, schema *cvalid
 func (c *HttpEndpoint) RegisterRouteWithAuth(method string, route string, schema *cvalid.Schema,
 	authorize func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc),
 	action http.HandlerFunc) {
-
-	if authorize != nil {
-		nextAction := action
-		action = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authorize(w, r, nextAction)
-		})
-	}
-
-	c.RegisterRoute(method, route, schema, action)
+	c.RegisterRouteWithAuthAndOptions(method, route, schema, nil, authorize, action)
 }
 
 // RegisterInterceptor method are registers a middleware action for the given route.
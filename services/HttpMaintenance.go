@@ -0,0 +1,143 @@
+package services
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	cconv "github.com/pip-services3-go/pip-services3-commons-go/convert"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+)
+
+// MaintenanceErrorCode is a code of error returned by endpoints in maintenance mode
+const MaintenanceErrorCode = "MAINTENANCE"
+
+// MaintenanceState describes the maintenance mode of HTTP endpoint
+type MaintenanceState struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// SetMaintenance method are turns maintenance mode on or off at runtime.
+// In maintenance mode all requests except allowed routes are rejected with 503 error.
+// Parameters:
+//   - enabled  bool    true to turn maintenance mode on.
+//   - reason   string  (optional) a reason reported to clients.
+func (c *HttpEndpoint) SetMaintenance(enabled bool, reason string) {
+	c.setMaintenance("", enabled, reason, "API call")
+}
+
+// IsMaintenance method checks if the endpoint is in maintenance mode.
+func (c *HttpEndpoint) IsMaintenance() bool {
+	c.maintenanceLock.RLock()
+	defer c.maintenanceLock.RUnlock()
+	return c.maintenanceEnabled
+}
+
+// GetMaintenance method returns the current maintenance mode with its reason.
+func (c *HttpEndpoint) GetMaintenance() MaintenanceState {
+	c.maintenanceLock.RLock()
+	defer c.maintenanceLock.RUnlock()
+	return MaintenanceState{Enabled: c.maintenanceEnabled, Reason: c.maintenanceReason}
+}
+
+// SetMaintenanceAuthorizer method are sets an authorization interceptor for the route
+// configured in "options.maintenance_route". The route is not registered without it,
+// so anonymous callers cannot turn maintenance mode on. It must be set before Open.
+// Parameters:
+//   - authorize  the authorization interceptor, i.e. Signed() of an auth manager.
+func (c *HttpEndpoint) SetMaintenanceAuthorizer(authorize func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)) {
+	c.maintenanceAuthorizer = authorize
+}
+
+func (c *HttpEndpoint) setMaintenance(correlationId string, enabled bool, reason string, by string) {
+	c.maintenanceLock.Lock()
+	c.maintenanceEnabled = enabled
+	c.maintenanceReason = reason
+	c.maintenanceLock.Unlock()
+
+	if enabled {
+		c.logger.Info(correlationId, "Maintenance mode of REST service at %s was turned on by %s: %s", c.uri, by, reason)
+	} else {
+		c.logger.Info(correlationId, "Maintenance mode of REST service at %s was turned off by %s", c.uri, by)
+	}
+}
+
+// Checks if the route stays available in maintenance mode.
+// Routes are matched exactly, so services with base routes must list full routes.
+func (c *HttpEndpoint) isMaintenanceAllowed(path string) bool {
+	path = strings.Trim(path, "/")
+	if c.maintenanceRoute != "" && c.maintenanceAuthorizer != nil && path == strings.Trim(c.maintenanceRoute, "/") {
+		return true
+	}
+	for _, route := range c.maintenanceAllowedRoutes {
+		if path == route {
+			return true
+		}
+	}
+	return false
+}
+
+// Rejects requests with 503 error when maintenance mode is on
+func (c *HttpEndpoint) doMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := c.GetMaintenance()
+		if !state.Enabled || c.isMaintenanceAllowed(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if c.maintenanceRetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(c.maintenanceRetryAfter))
+		}
+		err := cerr.NewInvalidStateError(c.GetCorrelationId(r), MaintenanceErrorCode,
+			"Service is under maintenance").WithStatus(http.StatusServiceUnavailable)
+		if state.Reason != "" {
+			err = err.WithDetails("reason", state.Reason)
+		}
+		HttpResponseSender.SendError(w, r, err)
+	})
+}
+
+// Returns or changes maintenance mode, registered on maintenance_route
+func (c *HttpEndpoint) maintenance(res http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet {
+		HttpResponseSender.SendResult(res, req, c.GetMaintenance(), nil)
+		return
+	}
+
+	correlationId := c.GetCorrelationId(req)
+	state := c.GetMaintenance()
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		HttpResponseSender.SendError(res, req, err)
+		return
+	}
+	if len(body) > 0 {
+		if err = HttpCodecs.ForRequest(req).Decode(body, &state); err != nil {
+			HttpResponseSender.SendError(res, req, cerr.NewBadRequestError(correlationId, "INVALID_MAINTENANCE",
+				"Failed to decode maintenance state").WithCause(err))
+			return
+		}
+	}
+	query := req.URL.Query()
+	if enabled := query.Get("enabled"); enabled != "" {
+		state.Enabled = cconv.BooleanConverter.ToBoolean(enabled)
+	}
+	if reason := query.Get("reason"); reason != "" {
+		state.Reason = reason
+	}
+	if !state.Enabled {
+		state.Reason = ""
+	}
+
+	by := HttpRequestDetector.DetectAddress(req)
+	if userId := UserIdFromRequest(req); userId != "" {
+		by = userId + " from " + by
+	}
+
+	c.setMaintenance(correlationId, state.Enabled, state.Reason, by)
+	HttpResponseSender.SendResult(res, req, c.GetMaintenance(), nil)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+)
+
+// UnmatchedRoute is the route name used for requests that did not match any registered route
+const UnmatchedRoute = "unmatched"
+
+// HttpDurationBuckets are upper bounds in seconds of request duration histogram buckets
+var HttpDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HttpRouteMetrics contains metrics of requests served by a route of HTTP endpoint
+type HttpRouteMetrics struct {
+	// HTTP method of the route.
+	Method string
+	// Route template, i.e. "/items/{id}", or "unmatched".
+	Route string
+	// Number of served requests.
+	Count int64
+	// Number of served requests by status classes: "1xx", "2xx", "3xx", "4xx" and "5xx".
+	StatusClasses map[string]int64
+	// Number of requests in flight.
+	InFlight int64
+	// Total duration of served requests in seconds.
+	DurationSum float64
+	// Cumulative numbers of requests with durations within HttpDurationBuckets.
+	DurationBuckets []int64
+	// Total size of request bodies in bytes.
+	RequestSize int64
+	// Total size of response bodies in bytes.
+	ResponseSize int64
+}
+
+/*
+httpMetrics records metrics of requests served by HTTP endpoint per route template
+into counters and keeps them for exposition.
+
+Counters are named "http_endpoint.<METHOD> <route>.<metric>", where metrics are
+count, time, in_flight, request_size, response_size and status classes 2xx, 4xx, 5xx, etc.
+Route templates are used instead of raw paths to keep the number of counters bounded.
+*/
+type httpMetrics struct {
+	enabled  bool
+	counters *ccount.CompositeCounters
+	lock     sync.Mutex
+	routes   map[string]*HttpRouteMetrics
+}
+
+func newHttpMetrics(counters *ccount.CompositeCounters) *httpMetrics {
+	return &httpMetrics{
+		enabled:  true,
+		counters: counters,
+		routes:   make(map[string]*HttpRouteMetrics),
+	}
+}
+
+func (c *httpMetrics) configure(config *cconf.ConfigParams) {
+	c.enabled = config.GetAsBooleanWithDefault("options.metrics.enabled", c.enabled)
+}
+
+// Limits methods to the standard ones, other methods may be sent by any client
+func normalizeMetricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+func (c *httpMetrics) route(method string, route string) (*HttpRouteMetrics, string) {
+	method = normalizeMetricsMethod(method)
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	key := method + " " + route
+
+	metrics, ok := c.routes[key]
+	if !ok {
+		metrics = &HttpRouteMetrics{
+			Method:          method,
+			Route:           route,
+			StatusClasses:   make(map[string]int64),
+			DurationBuckets: make([]int64, len(HttpDurationBuckets)),
+		}
+		c.routes[key] = metrics
+	}
+	return metrics, "http_endpoint." + key
+}
+
+// Counts the request in flight and returns a function to call when it is completed
+func (c *httpMetrics) begin(method string, route string) func() {
+	if !c.enabled {
+		return func() {}
+	}
+
+	c.lock.Lock()
+	metrics, name := c.route(method, route)
+	metrics.InFlight++
+	inFlight := metrics.InFlight
+	c.lock.Unlock()
+	c.counters.Last(name+".in_flight", float32(inFlight))
+
+	return func() {
+		c.lock.Lock()
+		metrics.InFlight--
+		inFlight := metrics.InFlight
+		c.lock.Unlock()
+		c.counters.Last(name+".in_flight", float32(inFlight))
+	}
+}
+
+// Records the served request
+func (c *httpMetrics) record(record *httpRequestRecord) {
+	if !c.enabled {
+		return
+	}
+
+	duration := record.duration.Seconds()
+	statusClass := string(rune('0'+record.status/100)) + "xx"
+
+	c.lock.Lock()
+	metrics, name := c.route(record.method, record.route)
+	metrics.Count++
+	metrics.StatusClasses[statusClass]++
+	metrics.DurationSum += duration
+	for i, bound := range HttpDurationBuckets {
+		if duration <= bound {
+			metrics.DurationBuckets[i]++
+		}
+	}
+	metrics.RequestSize += record.requestSize
+	metrics.ResponseSize += record.responseSize
+	c.lock.Unlock()
+
+	c.counters.IncrementOne(name + ".count")
+	c.counters.IncrementOne(name + "." + statusClass)
+	c.counters.EndTiming(name+".time", float32(record.duration.Microseconds())/1000)
+	c.counters.Stats(name+".request_size", float32(record.requestSize))
+	c.counters.Stats(name+".response_size", float32(record.responseSize))
+}
+
+// Returns copies of collected metrics sorted by route and method
+func (c *httpMetrics) snapshot() []HttpRouteMetrics {
+	c.lock.Lock()
+	result := make([]HttpRouteMetrics, 0, len(c.routes))
+	for _, metrics := range c.routes {
+		item := *metrics
+		item.StatusClasses = make(map[string]int64, len(metrics.StatusClasses))
+		for class, count := range metrics.StatusClasses {
+			item.StatusClasses[class] = count
+		}
+		item.DurationBuckets = append([]int64{}, metrics.DurationBuckets...)
+		result = append(result, item)
+	}
+	c.lock.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Route != result[j].Route {
+			return result[i].Route < result[j].Route
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
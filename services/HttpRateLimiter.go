@@ -0,0 +1,254 @@
+package services
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+	clog "github.com/pip-services3-go/pip-services3-components-go/log"
+)
+
+// TooManyRequestsErrorCode is a code of error returned when rate limit is exceeded
+const TooManyRequestsErrorCode = "TOO_MANY_REQUESTS"
+
+/*
+HttpRateLimiter limits rate of HTTP requests made by each client.
+
+Clients are identified by IP address, by authenticated user or by a request header.
+IP address is the remote address of the connection. Forwarding headers are used only
+for requests received from trusted proxies (see HttpRequestDetector.DetectForwardedAddress).
+Requests over the limit are rejected
+with 429 error and Retry-After header. All responses get X-RateLimit-Limit,
+X-RateLimit-Remaining and X-RateLimit-Reset headers.
+
+The limiter is used by HttpEndpoint to limit all routes and routes registered
+with RouteOptions.RateLimit. It can also be added to selected routes
+by RegisterInterceptor method.
+
+The endpoint-wide limit is checked before any authentication, so "user" key
+falls back to IP address there. To limit requests per user register Interceptor()
+after the authentication interceptor.
+
+Configuration parameters:
+
+  - options:
+  - rate_limit:
+  - enabled:                 turns on rate limiting of all endpoint routes (default: false)
+  - algorithm:               token_bucket or sliding_window (default: token_bucket)
+  - limit:                   maximum number of requests per client within the window (default: 100)
+  - window:                  time window in milliseconds (default: 60 sec)
+  - key:                     client key: ip, user or header:<name> (default: ip)
+  - trusted_proxies:         comma-separated IP addresses or CIDR networks of proxies trusted to set X-Forwarded-For and X-Real-IP headers (default: none)
+
+References:
+
+  - *:rate-limit-store:*:*:1.0   (optional) IRateLimitStore to share limits between instances, in-memory store is used by default
+
+Example:
+
+	limiter := NewHttpRateLimiter(nil, nil)
+	limiter.Configure(cconf.NewConfigParamsFromTuples(
+		"options.rate_limit.limit", 10,
+		"options.rate_limit.window", 1000,
+	))
+	service.RegisterInterceptor("/dummies", limiter.Interceptor())
+*/
+type HttpRateLimiter struct {
+	// Turns on rate limiting of all endpoint routes.
+	Enabled bool
+	// Rate limiting algorithm.
+	Algorithm string
+	// Maximum number of requests per client within the window.
+	Limit int
+	// Time window.
+	Window time.Duration
+	// Client key: ip, user or header:<name>.
+	Key string
+	// Networks of proxies trusted to set forwarding headers.
+	TrustedProxies []*net.IPNet
+	// (optional) Custom function to calculate client key.
+	KeyFunc func(req *http.Request) string
+
+	store    IRateLimitStore
+	logger   *clog.CompositeLogger
+	counters *ccount.CompositeCounters
+}
+
+// NewHttpRateLimiter creates a new instance of the rate limiter with in-memory store.
+// Parameters:
+//   - logger    *clog.CompositeLogger      (optional) a logger to report store failures.
+//   - counters  *ccount.CompositeCounters  (optional) counters to report rejected requests.
+//
+// Returns: *HttpRateLimiter
+func NewHttpRateLimiter(logger *clog.CompositeLogger, counters *ccount.CompositeCounters) *HttpRateLimiter {
+	if logger == nil {
+		logger = clog.NewCompositeLogger()
+	}
+	if counters == nil {
+		counters = ccount.NewCompositeCounters()
+	}
+	return &HttpRateLimiter{
+		Enabled:   false,
+		Algorithm: RateLimitTokenBucket,
+		Limit:     100,
+		Window:    60 * time.Second,
+		Key:       "ip",
+		store:     NewMemoryRateLimitStore(),
+		logger:    logger,
+		counters:  counters,
+	}
+}
+
+// Configure method are configures the rate limiter by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *HttpRateLimiter) Configure(config *cconf.ConfigParams) {
+	config = config.GetSection("options.rate_limit")
+
+	c.Enabled = config.GetAsBooleanWithDefault("enabled", c.Enabled)
+	c.Algorithm = strings.ToLower(config.GetAsStringWithDefault("algorithm", c.Algorithm))
+	c.Limit = config.GetAsIntegerWithDefault("limit", c.Limit)
+	c.Window = time.Duration(config.GetAsLongWithDefault("window", int64(c.Window/time.Millisecond))) * time.Millisecond
+	c.Key = config.GetAsStringWithDefault("key", c.Key)
+	if proxies := config.GetAsNullableString("trusted_proxies"); proxies != nil {
+		var err error
+		c.TrustedProxies, err = ParseTrustedProxies(*proxies)
+		if err != nil {
+			c.logger.Warn("", "Skipped invalid rate limit trusted proxies: %s", err.Error())
+		}
+	}
+}
+
+// SetReferences method are sets references to a shared rate limit store.
+// Parameters:
+//   - references  crefer.IReferences  references to locate the component dependencies.
+func (c *HttpRateLimiter) SetReferences(references crefer.IReferences) {
+	for _, reference := range references.GetOptional(crefer.NewDescriptor("*", "rate-limit-store", "*", "*", "1.0")) {
+		if store, ok := reference.(IRateLimitStore); ok {
+			c.store = store
+			break
+		}
+	}
+}
+
+// SetStore method are sets a store to keep rate limiting state.
+// Parameters:
+//   - store  IRateLimitStore  a store to use.
+func (c *HttpRateLimiter) SetStore(store IRateLimitStore) {
+	c.store = store
+}
+
+// Handler method are wraps HTTP handler with rate limiting when the limiter is enabled.
+// Parameters:
+//   - next  http.Handler  a handler to wrap.
+//
+// Returns: http.Handler
+func (c *HttpRateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+		c.limit(w, r, "*", c.Limit, c.Window, next.ServeHTTP)
+	})
+}
+
+// Interceptor method returns an interceptor that applies configured limit to routes
+// it is registered for. The limit is shared by all those routes.
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *HttpRateLimiter) Interceptor() func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	return c.LimitRoute("interceptor", c.Limit, c.Window)
+}
+
+// LimitRoute method returns an interceptor with the limit specific for a route.
+// Parameters:
+//   - scope   string         a name that separates the limit from other routes.
+//   - limit   int            maximum number of requests per client within the window.
+//   - window  time.Duration  the time window, 0 to use the configured window.
+//
+// Returns: func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc)
+func (c *HttpRateLimiter) LimitRoute(scope string, limit int,
+	window time.Duration) func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+
+	return func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		routeWindow := window
+		if routeWindow <= 0 {
+			routeWindow = c.Window
+		}
+		c.limit(res, req, scope, limit, routeWindow, next)
+	}
+}
+
+func (c *HttpRateLimiter) limit(res http.ResponseWriter, req *http.Request, scope string,
+	limit int, window time.Duration, next http.HandlerFunc) {
+
+	if limit <= 0 || window <= 0 {
+		next(res, req)
+		return
+	}
+
+	correlationId := req.URL.Query().Get("correlation_id")
+	if correlationId == "" {
+		correlationId = req.Header.Get("correlation_id")
+	}
+
+	key := "rate_limit:" + scope + ":" + c.clientKey(req)
+	result, err := c.store.Take(correlationId, key, c.Algorithm, limit, window)
+	if err != nil {
+		// Do not block clients when the store is not available
+		c.logger.Warn(correlationId, "Failed to check rate limit for %s: %s", key, err.Error())
+		next(res, req)
+		return
+	}
+
+	header := res.Header()
+	header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(result.Reset.Seconds())), 10))
+
+	if result.Allowed {
+		next(res, req)
+		return
+	}
+
+	retryAfter := int64(math.Ceil(result.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	header.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.counters.IncrementOne("http_endpoint.rate_limited")
+	HttpResponseSender.SendError(res, req,
+		cerr.NewUnknownError(correlationId, TooManyRequestsErrorCode, "Too many requests, rate limit is exceeded").
+			WithDetails("limit", result.Limit).
+			WithDetails("retry_after", retryAfter).
+			WithStatus(http.StatusTooManyRequests))
+}
+
+// Calculates key that identifies the client
+func (c *HttpRateLimiter) clientKey(req *http.Request) string {
+	if c.KeyFunc != nil {
+		if key := c.KeyFunc(req); key != "" {
+			return key
+		}
+	}
+
+	key := strings.ToLower(c.Key)
+	switch {
+	case key == "user":
+		if userId := UserIdFromRequest(req); userId != "" {
+			return "user:" + userId
+		}
+	case strings.HasPrefix(key, "header:"):
+		if value := req.Header.Get(strings.TrimSpace(c.Key[len("header:"):])); value != "" {
+			return "header:" + value
+		}
+	}
+	return "ip:" + HttpRequestDetector.DetectForwardedAddress(req, c.TrustedProxies)
+}
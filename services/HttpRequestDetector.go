@@ -1,8 +1,12 @@
 package services
 
 import (
+	"net"
 	"net/http"
 	"regexp"
+	"strings"
+
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
 )
 
 /*
@@ -127,44 +131,108 @@ func (c *THttpRequestDetector) DetectBrowser(req *http.Request) string {
 }
 
 // DetectAddress method are detects the IP address from which the given HTTP request was received.
+// Only the remote address of the connection is used, because forwarding headers
+// are set by clients and cannot be trusted. Use DetectForwardedAddress behind proxies.
 //   -  req *http.Reques an HTTP request to process.
 //   Returns the detected IP address (without a port). If no IP is detected -
-// nil will be returned.
+// empty string will be returned.
 func (c *THttpRequestDetector) DetectAddress(req *http.Request) string {
-	var ip string
-	// TODO: need to write!!
-
-	// if req.headers["x-forwarded-for"] {
-	// 	ip = req.headers["x-forwarded-for"].split(",")[0]
-	// }
-
-	// if ip == nil && req.ip {
-	// 	ip = req.ip
-	// }
-
-	// if ip == nil && req.connection {
-	// 	ip = req.connection.remoteAddress
-	// 	if !ip && req.connection.socket {
-	// 		ip = req.connection.socket.remoteAddress
-	// 	}
-	// }
-
-	// if ip == nil && req.socket {
-	// 	ip = req.socket.remoteAddress
-	// }
-
-	// // Remove port
-	// if ip != nil {
-	// 	ip = ip.toString()
-	// 	var index = ip.indexOf(":")
-	// 	if index > 0 {
-	// 		ip = ip.substring(0, index)
-	// 	}
-	// }
+	ip := req.RemoteAddr
+	// Remove port
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return ip
+}
 
+// DetectForwardedAddress method are detects the IP address of the client when requests come through proxies.
+// "X-Forwarded-For" and "X-Real-IP" headers are used only when the remote address belongs to trusted proxies.
+// The right-most address in "X-Forwarded-For" header that is not a trusted proxy is taken as the client address.
+//   -  req *http.Request an HTTP request to process.
+//   -  trustedProxies []*net.IPNet networks of trusted proxies.
+//   Returns the detected IP address (without a port). If no IP is detected -
+// empty string will be returned.
+func (c *THttpRequestDetector) DetectForwardedAddress(req *http.Request, trustedProxies []*net.IPNet) string {
+	ip := c.DetectAddress(req)
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	forwarded := req.Header.Values("X-Forwarded-For")
+	if len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if host, _, err := net.SplitHostPort(hop); err == nil {
+				hop = host
+			}
+			ip = hop
+			if !isTrustedProxy(hop, trustedProxies) {
+				break
+			}
+		}
+		return ip
+	}
+
+	if realIp := strings.TrimSpace(req.Header.Get("X-Real-IP")); realIp != "" {
+		return realIp
+	}
 	return ip
 }
 
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR networks of trusted proxies.
+// Parameters:
+//   - value  string  a list of addresses, i.e. "10.0.0.0/8, 192.168.1.10".
+//
+// Returns: []*net.IPNet, error
+// parsed networks and an error for invalid entries, that are skipped.
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0)
+	invalid := make([]string, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip = ip.To4()
+					bits = 8 * net.IPv4len
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		} else if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		invalid = append(invalid, entry)
+	}
+	if len(invalid) > 0 {
+		return networks, cerr.NewConfigError("", "INVALID_TRUSTED_PROXIES", "Trusted proxies are not valid IP addresses or networks").
+			WithDetails("proxies", strings.Join(invalid, ","))
+	}
+	return networks, nil
+}
+
+func isTrustedProxy(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // DetectServerHost method are detects the host name of the request"s destination server.
 //   -  req *http.Request  an HTTP request to process.
 //   Returns the destination server"s host name.
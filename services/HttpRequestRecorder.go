@@ -0,0 +1,113 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Information about a served HTTP request collected for logging and metrics
+type httpRequestRecord struct {
+	method        string
+	path          string
+	route         string
+	correlationId string
+	userId        string
+	status        int
+	requestSize   int64
+	responseSize  int64
+	started       time.Time
+	duration      time.Duration
+}
+
+type httpRequestRecordKey struct{}
+
+// Response writer that records status and size of the response
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	record      *httpRequestRecord
+	wroteHeader bool
+}
+
+func (c *recordingResponseWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		c.record.status = status
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *recordingResponseWriter) Write(data []byte) (int, error) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		c.record.status = http.StatusOK
+	}
+	n, err := c.ResponseWriter.Write(data)
+	c.record.responseSize += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data to the client
+func (c *recordingResponseWriter) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection, i.e. for protocol upgrades
+func (c *recordingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	c.record.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Records requests served by the endpoint and passes records to access log and metrics
+func (c *HttpEndpoint) recordRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := &httpRequestRecord{
+			method:        r.Method,
+			path:          r.URL.Path,
+			correlationId: c.GetCorrelationId(r),
+			status:        http.StatusOK,
+			requestSize:   r.ContentLength,
+			started:       time.Now(),
+		}
+		if record.requestSize < 0 {
+			record.requestSize = 0
+		}
+		writer := &recordingResponseWriter{ResponseWriter: w, record: record}
+		r = r.WithContext(context.WithValue(r.Context(), httpRequestRecordKey{}, record))
+
+		defer func() {
+			record.duration = time.Since(record.started)
+			c.metrics.record(record)
+			c.accessLog.Log(r, record)
+		}()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// Captures route template of the matched route to avoid high cardinality of raw paths
+// and counts requests in flight per route
+func (c *HttpEndpoint) captureRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if record, ok := r.Context().Value(httpRequestRecordKey{}).(*httpRequestRecord); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					record.route = template
+				}
+			}
+			done := c.metrics.begin(record.method, record.route)
+			defer done()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
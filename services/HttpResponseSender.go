@@ -1,8 +1,6 @@
 package services
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
 	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
@@ -24,15 +22,32 @@ type THttpResponseSender struct {
 //   - err  error     an error object to be sent.
 func (c *THttpResponseSender) SendError(res http.ResponseWriter, req *http.Request, err error) {
 	appErr := cerr.ErrorDescriptionFactory.Create(err)
-	res.Header().Add("Content-Type", "application/json")
-	res.WriteHeader(appErr.Status)
-	jsonObj, jsonErr := json.Marshal(appErr)
-	if jsonErr == nil {
-		io.WriteString(res, (string)(jsonObj))
+	c.writeBody(res, req, appErr.Status, appErr)
+}
+
+// Encodes the value with codec negotiated from Accept header of the request
+// and writes it with the given status code. JSON is used when the negotiated codec fails.
+func (c *THttpResponseSender) writeBody(res http.ResponseWriter, req *http.Request, status int, value interface{}) {
+	codec := HttpCodecs.ForResponse(req)
+	data, encErr := codec.Encode(value)
+	if encErr != nil && codec != HttpCodecs.Default() {
+		codec = HttpCodecs.Default()
+		data, encErr = codec.Encode(value)
+	}
+	res.Header().Add("Content-Type", codec.ContentType())
+	res.WriteHeader(status)
+	if encErr == nil {
+		res.Write(data)
 	}
 }
 
-// SendResult sends result as JSON object.
+// Writes empty response with content type negotiated from Accept header of the request
+func (c *THttpResponseSender) writeEmpty(res http.ResponseWriter, req *http.Request, status int) {
+	res.Header().Add("Content-Type", HttpCodecs.ForResponse(req).ContentType())
+	res.WriteHeader(status)
+}
+
+// SendResult sends result encoded as JSON or other format negotiated by Accept header.
 // That function call be called directly or passed
 // as a parameter to business logic components.
 // If object is not nil it returns 200 status code.
@@ -49,14 +64,9 @@ func (c *THttpResponseSender) SendResult(res http.ResponseWriter, req *http.Requ
 		return
 	}
 	if result == nil {
-		res.Header().Add("Content-Type", "application/json")
-		res.WriteHeader(204)
+		c.writeEmpty(res, req, 204)
 	} else {
-		res.Header().Add("Content-Type", "application/json")
-		jsonObj, jsonErr := json.Marshal(result)
-		if jsonErr == nil {
-			io.WriteString(res, (string)(jsonObj))
-		}
+		c.writeBody(res, req, 200, result)
 	}
 }
 
@@ -69,8 +79,7 @@ func (c *THttpResponseSender) SendEmptyResult(res http.ResponseWriter, req *http
 		HttpResponseSender.SendError(res, req, err)
 		return
 	}
-	res.Header().Add("Content-Type", "application/json")
-	res.WriteHeader(204)
+	c.writeEmpty(res, req, 204)
 }
 
 // SendCreatedResult are sends newly created object as JSON.
@@ -88,15 +97,9 @@ func (c *THttpResponseSender) SendCreatedResult(res http.ResponseWriter, req *ht
 		return
 	}
 	if result == nil {
-		res.Header().Add("Content-Type", "application/json")
-		res.WriteHeader(204)
+		c.writeEmpty(res, req, 204)
 	} else {
-		res.Header().Add("Content-Type", "application/json")
-		res.WriteHeader(201)
-		jsonObj, jsonErr := json.Marshal(result)
-		if jsonErr == nil {
-			io.WriteString(res, (string)(jsonObj))
-		}
+		c.writeBody(res, req, 201, result)
 	}
 }
 
@@ -115,14 +118,8 @@ func (c *THttpResponseSender) SendDeletedResult(res http.ResponseWriter, req *ht
 		return
 	}
 	if result == nil {
-		res.Header().Add("Content-Type", "application/json")
-		res.WriteHeader(204)
+		c.writeEmpty(res, req, 204)
 	} else {
-		res.Header().Add("Content-Type", "application/json")
-		res.WriteHeader(200)
-		jsonObj, jsonErr := json.Marshal(result)
-		if jsonErr == nil {
-			io.WriteString(res, (string)(jsonObj))
-		}
+		c.writeBody(res, req, 200, result)
 	}
 }
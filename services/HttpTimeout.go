@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+)
+
+// TimeoutErrorCode is a code of error returned when request processing exceeds the timeout
+const TimeoutErrorCode = "TIMEOUT"
+
+// Runs the handler with the time limit. The request context is cancelled on timeout
+// and the client gets 504 error. Responses are buffered until the handler completes,
+// so streaming and hijacking are not available for routes with timeouts.
+func timeoutHandler(next http.Handler, timeout time.Duration, correlationId func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		writer := &timeoutWriter{
+			header: make(http.Header),
+			status: http.StatusOK,
+		}
+		done := make(chan struct{})
+		panics := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panics <- p
+				}
+			}()
+			next.ServeHTTP(writer, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panics:
+			panic(p)
+		case <-done:
+			writer.lock.Lock()
+			defer writer.lock.Unlock()
+			header := w.Header()
+			for key, values := range writer.header {
+				header[key] = values
+			}
+			w.WriteHeader(writer.status)
+			w.Write(writer.body.Bytes())
+		case <-ctx.Done():
+			writer.lock.Lock()
+			defer writer.lock.Unlock()
+			writer.timedOut = true
+			if r.Context().Err() == context.DeadlineExceeded {
+				HttpResponseSender.SendError(w, r,
+					cerr.NewUnknownError(correlationId(r), TimeoutErrorCode, "Request processing timed out").
+						WithDetails("timeout", timeout.Milliseconds()).
+						WithStatus(http.StatusGatewayTimeout))
+			}
+		}
+	})
+}
+
+// Buffers response of handler running with timeout
+type timeoutWriter struct {
+	lock        sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (c *timeoutWriter) Header() http.Header {
+	return c.header
+}
+
+func (c *timeoutWriter) Write(data []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	c.wroteHeader = true
+	return c.body.Write(data)
+}
+
+func (c *timeoutWriter) WriteHeader(status int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.timedOut || c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+	c.status = status
+}
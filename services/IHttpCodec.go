@@ -0,0 +1,12 @@
+package services
+
+// IHttpCodec interface for encoders and decoders of HTTP message bodies
+// registered in HttpCodecs registry.
+type IHttpCodec interface {
+	// ContentType returns MIME type written into Content-Type header
+	ContentType() string
+	// Encode serializes a value into message body
+	Encode(value interface{}) ([]byte, error)
+	// Decode deserializes message body into the target pointer
+	Decode(data []byte, target interface{}) error
+}
@@ -23,3 +23,14 @@ type IHttpEndpoint interface {
 	RegisterInterceptor(route string, action func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc))
 	AddCorsHeader(header string, origin string)
 }
+
+// IRouteOptionsEndpoint optional interface for endpoints that support
+// per-route settings. Endpoints that do not implement it get routes
+// registered with default settings.
+type IRouteOptionsEndpoint interface {
+	RegisterRouteWithOptions(method string, route string, schema *cvalid.Schema,
+		options *RouteOptions, action http.HandlerFunc)
+	RegisterRouteWithAuthAndOptions(method string, route string, schema *cvalid.Schema,
+		options *RouteOptions, authorize func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc),
+		action http.HandlerFunc)
+}
@@ -0,0 +1,40 @@
+package services
+
+import "time"
+
+const (
+	// RateLimitTokenBucket is a token bucket algorithm that allows bursts up to the limit
+	// and refills tokens evenly within the window
+	RateLimitTokenBucket = "token_bucket"
+	// RateLimitSlidingWindow is a sliding window algorithm that counts requests
+	// within the last window
+	RateLimitSlidingWindow = "sliding_window"
+)
+
+// RateLimitResult is a result of rate limit check
+type RateLimitResult struct {
+	// True if the request is allowed
+	Allowed bool
+	// Maximum number of requests within the window
+	Limit int
+	// Number of requests left within the window
+	Remaining int
+	// Time until the limit is fully restored
+	Reset time.Duration
+	// Time to wait before the next request is allowed
+	RetryAfter time.Duration
+}
+
+// IRateLimitStore is an interface for stores that keep rate limiting state.
+// Implement it to share limits between multiple service instances, i.e. in Redis.
+type IRateLimitStore interface {
+	// Take tries to consume one request for the given key.
+	// Parameters:
+	//   - correlationId  string         (optional) transaction id to trace execution through call chain.
+	//   - key            string         a key that identifies the client and the route.
+	//   - algorithm      string         RateLimitTokenBucket or RateLimitSlidingWindow.
+	//   - limit          int            maximum number of requests within the window.
+	//   - window         time.Duration  the time window.
+	// Returns: *RateLimitResult, error
+	Take(correlationId string, key string, algorithm string, limit int, window time.Duration) (*RateLimitResult, error)
+}
@@ -14,6 +14,7 @@ type InstrumentTiming struct {
 	counters      ccount.ICounters
 	counterTiming *ccount.CounterTiming
 	traceTiming   *ctrace.TraceTiming
+	span          *SpanContext
 }
 
 func NewInstrumentTiming(correlationId string, name string,
@@ -34,6 +35,17 @@ func NewInstrumentTiming(correlationId string, name string,
 	}
 }
 
+// WithSpan sets the span of distributed trace created for the instrumented operation.
+func (c *InstrumentTiming) WithSpan(span *SpanContext) *InstrumentTiming {
+	c.span = span
+	return c
+}
+
+// Span gets the span of distributed trace created for the instrumented operation or nil.
+func (c *InstrumentTiming) Span() *SpanContext {
+	return c.span
+}
+
 func (c *InstrumentTiming) clear() {
 	// Clear references to avoid double processing
 	c.counters = nil
@@ -50,6 +62,14 @@ func (c *InstrumentTiming) EndTiming(err error) {
 	}
 }
 
+// Logs completion of the operation with its trace and span ids
+func (c *InstrumentTiming) traceSpan() {
+	if c.span != nil && c.logger != nil {
+		c.logger.Trace(c.correlationId, "Completed %s method in trace %s span %s",
+			c.name, c.span.TraceId, c.span.SpanId)
+	}
+}
+
 func (c *InstrumentTiming) EndSuccess() {
 	if c.counterTiming != nil {
 		c.counterTiming.EndTiming()
@@ -57,6 +77,7 @@ func (c *InstrumentTiming) EndSuccess() {
 	if c.traceTiming != nil {
 		c.traceTiming.EndTrace()
 	}
+	c.traceSpan()
 
 	c.clear()
 }
@@ -68,7 +89,12 @@ func (c *InstrumentTiming) EndFailure(err error) {
 
 	if err != nil {
 		if c.logger != nil {
-			c.logger.Error(c.correlationId, err, "Failed to call %s method", c.name)
+			if c.span != nil {
+				c.logger.Error(c.correlationId, err, "Failed to call %s method in trace %s span %s",
+					c.name, c.span.TraceId, c.span.SpanId)
+			} else {
+				c.logger.Error(c.correlationId, err, "Failed to call %s method", c.name)
+			}
 		}
 		if c.counters != nil {
 			c.counters.IncrementOne(c.name + "." + c.verb + "_errors")
@@ -80,6 +106,7 @@ func (c *InstrumentTiming) EndFailure(err error) {
 		if c.traceTiming != nil {
 			c.traceTiming.EndTrace()
 		}
+		c.traceSpan()
 	}
 
 	c.clear()
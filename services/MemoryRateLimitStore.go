@@ -0,0 +1,144 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+/*
+MemoryRateLimitStore keeps rate limiting state in memory of the process.
+It supports token bucket and sliding window algorithms.
+Stale keys are removed periodically.
+
+See IRateLimitStore
+*/
+type MemoryRateLimitStore struct {
+	lock      sync.Mutex
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+type rateLimitBucket struct {
+	// Token bucket state
+	tokens    float64
+	updatedAt time.Time
+	// Sliding window state
+	windowStart   time.Time
+	currentCount  int
+	previousCount int
+	// Time after which the bucket can be removed
+	expiresAt time.Time
+}
+
+// NewMemoryRateLimitStore creates a new instance of the store.
+// Returns: *MemoryRateLimitStore
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*rateLimitBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take tries to consume one request for the given key.
+// See IRateLimitStore.Take
+func (c *MemoryRateLimitStore) Take(correlationId string, key string, algorithm string,
+	limit int, window time.Duration) (*RateLimitResult, error) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	c.sweep(now, window)
+
+	bucket, ok := c.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{
+			tokens:      float64(limit),
+			updatedAt:   now,
+			windowStart: now.Truncate(window),
+		}
+		c.buckets[key] = bucket
+	}
+	bucket.expiresAt = now.Add(2 * window)
+
+	if algorithm == RateLimitSlidingWindow {
+		return c.takeSlidingWindow(bucket, now, limit, window), nil
+	}
+	return c.takeTokenBucket(bucket, now, limit, window), nil
+}
+
+func (c *MemoryRateLimitStore) takeTokenBucket(bucket *rateLimitBucket, now time.Time,
+	limit int, window time.Duration) *RateLimitResult {
+
+	capacity := float64(limit)
+	rate := capacity / window.Seconds()
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	bucket.updatedAt = now
+
+	result := &RateLimitResult{Limit: limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration((capacity - bucket.tokens) / rate * float64(time.Second))
+	return result
+}
+
+func (c *MemoryRateLimitStore) takeSlidingWindow(bucket *rateLimitBucket, now time.Time,
+	limit int, window time.Duration) *RateLimitResult {
+
+	windowStart := now.Truncate(window)
+	if !windowStart.Equal(bucket.windowStart) {
+		if windowStart.Sub(bucket.windowStart) == window {
+			bucket.previousCount = bucket.currentCount
+		} else {
+			bucket.previousCount = 0
+		}
+		bucket.currentCount = 0
+		bucket.windowStart = windowStart
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(window)
+	estimated := float64(bucket.previousCount)*weight + float64(bucket.currentCount)
+
+	result := &RateLimitResult{
+		Limit: limit,
+		Reset: window - elapsed,
+	}
+	if estimated+1 <= float64(limit) {
+		bucket.currentCount++
+		result.Allowed = true
+		result.Remaining = int(float64(limit) - estimated - 1)
+		return result
+	}
+
+	// Wait until previous window requests slide out or the current window ends
+	result.RetryAfter = window - elapsed
+	if bucket.currentCount+1 <= limit && bucket.previousCount > 0 {
+		allowedWeight := float64(limit-bucket.currentCount-1) / float64(bucket.previousCount)
+		wait := time.Duration((1-allowedWeight)*float64(window)) - elapsed
+		if wait < result.RetryAfter {
+			result.RetryAfter = wait
+		}
+	}
+	return result
+}
+
+// Removes stale buckets not more often than once per window
+func (c *MemoryRateLimitStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(c.lastSweep) < window {
+		return
+	}
+	c.lastSweep = now
+	for key, bucket := range c.buckets {
+		if now.After(bucket.expiresAt) {
+			delete(c.buckets, key)
+		}
+	}
+}
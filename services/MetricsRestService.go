@@ -0,0 +1,280 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+)
+
+// PrometheusContentType is the content type of Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+/*
+MetricsRestService is a service that exposes metrics in Prometheus text exposition format
+via HTTP/REST protocol.
+
+The service responds on /metrics route (can be changed) with:
+  - per-route metrics collected by the HTTP endpoint: http_requests_total,
+    http_requests_in_flight, http_request_duration_seconds histogram,
+    http_request_size_bytes_total and http_response_size_bytes_total
+    labeled by method, route template and status class
+  - counters collected by referenced cached counters components (i.e. LogCounters).
+    Increment counters are exposed as counters, last value and timestamp counters as gauges,
+    interval and statistics counters as *_count, *_min, *_max and *_average gauges.
+    Per-route counters of the HTTP endpoint are skipped as they are already exposed with labels.
+
+Metric names are sanitized to match Prometheus naming rules,
+i.e. "mycomponent.exec_time" becomes "mycomponent_exec_time".
+
+Configuration parameters:
+
+  - baseroute:              base route for remote URI
+  - route:                   metrics route (default: "metrics")
+  - prefix:                  prefix added to names of all metrics (default: "")
+  - dependencies:
+    - endpoint:              override for HTTP Endpoint dependency
+    - counters:              override for cached counters dependency
+  - connection(s):
+    - discovery_key:         (optional) a key to retrieve the connection from IDiscovery
+    - protocol:              connection protocol: http or https
+    - host:                  host name or IP address
+    - port:                  port number
+    - uri:                   resource URI or connection string with all parameters in it
+
+References:
+
+- *:logger:*:*:1.0               (optional) ILogger components to pass log messages
+- *:counters:*:*:1.0             (optional) ICounters components, cached counters are exposed by the service
+- *:discovery:*:*:1.0            (optional) IDiscovery services to resolve connection
+- *:endpoint:http:*:1.0          (optional) HttpEndpoint reference
+
+See: RestService
+See: HttpEndpoint
+
+Example:
+
+    service := NewMetricsRestService();
+    service.Configure(cconf.NewConfigParamsFromTuples(
+        "prefix", "myservice_",
+        "connection.protocol", "http",
+        "connection.host", "localhost",
+        "connection.port", 8080,
+    ));
+
+	opnErr := service.Open("123")
+	if opnErr == nil {
+       fmt.Println("The Metrics service is accessible at http://localhost:8080/metrics");
+    }
+*/
+type MetricsRestService struct {
+	*RestService
+	route    string
+	prefix   string
+	counters []ICachedCounters
+}
+
+// ICachedCounters is implemented by counters components that keep collected counters, i.e. CachedCounters.
+type ICachedCounters interface {
+	// GetAll gets all captured counters.
+	GetAll() []*ccount.Counter
+}
+
+// NewMetricsRestService method are creates a new instance of this service.
+func NewMetricsRestService() *MetricsRestService {
+	c := &MetricsRestService{}
+	c.RestService = InheritRestService(c)
+	c.route = "metrics"
+	c.DependencyResolver.Put("counters", crefer.NewDescriptor("*", "counters", "*", "*", "1.0"))
+	return c
+}
+
+// Configure method are configures component by passing configuration parameters.
+// Parameters:
+//   - config  *cconf.ConfigParams  configuration parameters to be set.
+func (c *MetricsRestService) Configure(config *cconf.ConfigParams) {
+	c.RestService.Configure(config)
+	c.route = config.GetAsStringWithDefault("route", c.route)
+	c.prefix = config.GetAsStringWithDefault("prefix", c.prefix)
+}
+
+// SetReferences method are sets references to dependent components.
+// Parameters:
+//   - references crefer.IReferences	references to locate the component dependencies.
+func (c *MetricsRestService) SetReferences(references crefer.IReferences) {
+	c.RestService.SetReferences(references)
+
+	c.counters = make([]ICachedCounters, 0)
+	for _, ref := range c.DependencyResolver.GetOptional("counters") {
+		if counters, ok := ref.(ICachedCounters); ok {
+			c.counters = append(c.counters, counters)
+		}
+	}
+}
+
+// Register method are registers all service routes in HTTP endpoint.
+func (c *MetricsRestService) Register() {
+	c.RegisterRoute("get", c.route, nil, c.metrics)
+}
+
+// Handles metrics requests
+//   - req  *http.Request an HTTP request
+//   - res  http.ResponseWriter  an HTTP response
+func (c *MetricsRestService) metrics(res http.ResponseWriter, req *http.Request) {
+	buffer := &bytes.Buffer{}
+
+	if endpoint, ok := c.Endpoint.(interface{ Metrics() []HttpRouteMetrics }); ok {
+		c.writeHttpMetrics(buffer, endpoint.Metrics())
+	}
+	seen := make(map[string]bool)
+	for _, counters := range c.counters {
+		c.writeCounters(buffer, counters.GetAll(), seen)
+	}
+
+	res.Header().Set("Content-Type", PrometheusContentType)
+	res.WriteHeader(http.StatusOK)
+	res.Write(buffer.Bytes())
+}
+
+func (c *MetricsRestService) writeHttpMetrics(buffer *bytes.Buffer, metrics []HttpRouteMetrics) {
+	if len(metrics) == 0 {
+		return
+	}
+
+	name := c.prefix + "http_requests_total"
+	writeMetricHeader(buffer, name, "counter", "Number of served HTTP requests.")
+	for _, route := range metrics {
+		classes := make([]string, 0, len(route.StatusClasses))
+		for class := range route.StatusClasses {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			writeMetric(buffer, name, routeLabels(route, "status_class", class), float64(route.StatusClasses[class]))
+		}
+	}
+
+	name = c.prefix + "http_requests_in_flight"
+	writeMetricHeader(buffer, name, "gauge", "Number of HTTP requests in flight.")
+	for _, route := range metrics {
+		writeMetric(buffer, name, routeLabels(route), float64(route.InFlight))
+	}
+
+	name = c.prefix + "http_request_duration_seconds"
+	writeMetricHeader(buffer, name, "histogram", "Duration of served HTTP requests in seconds.")
+	for _, route := range metrics {
+		for i, bound := range HttpDurationBuckets {
+			writeMetric(buffer, name+"_bucket",
+				routeLabels(route, "le", strconv.FormatFloat(bound, 'g', -1, 64)), float64(route.DurationBuckets[i]))
+		}
+		writeMetric(buffer, name+"_bucket", routeLabels(route, "le", "+Inf"), float64(route.Count))
+		writeMetric(buffer, name+"_sum", routeLabels(route), route.DurationSum)
+		writeMetric(buffer, name+"_count", routeLabels(route), float64(route.Count))
+	}
+
+	name = c.prefix + "http_request_size_bytes_total"
+	writeMetricHeader(buffer, name, "counter", "Total size of HTTP request bodies in bytes.")
+	for _, route := range metrics {
+		writeMetric(buffer, name, routeLabels(route), float64(route.RequestSize))
+	}
+
+	name = c.prefix + "http_response_size_bytes_total"
+	writeMetricHeader(buffer, name, "counter", "Total size of HTTP response bodies in bytes.")
+	for _, route := range metrics {
+		writeMetric(buffer, name, routeLabels(route), float64(route.ResponseSize))
+	}
+}
+
+func (c *MetricsRestService) writeCounters(buffer *bytes.Buffer, counters []*ccount.Counter, seen map[string]bool) {
+	sort.Slice(counters, func(i, j int) bool { return counters[i].Name < counters[j].Name })
+
+	for _, counter := range counters {
+		name := sanitizeMetricName(c.prefix + counter.Name)
+		if isRouteCounter(counter.Name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		help := "Counter " + counter.Name + "."
+
+		switch counter.Type {
+		case ccount.Increment:
+			writeMetricHeader(buffer, name, "counter", help)
+			writeMetric(buffer, name, "", float64(counter.Count))
+		case ccount.LastValue:
+			writeMetricHeader(buffer, name, "gauge", help)
+			writeMetric(buffer, name, "", float64(counter.Last))
+		case ccount.Timestamp:
+			writeMetricHeader(buffer, name, "gauge", help)
+			writeMetric(buffer, name, "", float64(counter.Time.UnixNano())/1e9)
+		case ccount.Interval, ccount.Statistics:
+			values := []struct {
+				suffix string
+				value  float64
+			}{
+				{"_count", float64(counter.Count)},
+				{"_min", float64(counter.Min)},
+				{"_max", float64(counter.Max)},
+				{"_average", float64(counter.Average)},
+			}
+			for _, value := range values {
+				writeMetricHeader(buffer, name+value.suffix, "gauge", help)
+				writeMetric(buffer, name+value.suffix, "", value.value)
+			}
+		}
+	}
+}
+
+// Checks if the counter is a per-route counter recorded by HTTP endpoint, i.e. "http_endpoint.GET /items.count"
+func isRouteCounter(name string) bool {
+	return strings.HasPrefix(name, "http_endpoint.") && strings.Contains(name, " ")
+}
+
+func writeMetricHeader(buffer *bytes.Buffer, name string, typ string, help string) {
+	help = strings.NewReplacer("\\", "\\\\", "\n", "\\n").Replace(help)
+	fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeMetric(buffer *bytes.Buffer, name string, labels string, value float64) {
+	buffer.WriteString(name)
+	if labels != "" {
+		buffer.WriteString("{" + labels + "}")
+	}
+	buffer.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+func routeLabels(route HttpRouteMetrics, extra ...string) string {
+	labels := []string{"method", route.Method, "route", route.Route}
+	labels = append(labels, extra...)
+
+	var builder strings.Builder
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(labels[i] + "=\"" + escapeLabelValue(labels[i+1]) + "\"")
+	}
+	return builder.String()
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value)
+}
+
+// Replaces characters not allowed in Prometheus metric names with underscores
+func sanitizeMetricName(name string) string {
+	result := []byte(name)
+	for i, ch := range result {
+		valid := ch == '_' || ch == ':' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') ||
+			(i > 0 && ch >= '0' && ch <= '9')
+		if !valid {
+			result[i] = '_'
+		}
+	}
+	return string(result)
+}
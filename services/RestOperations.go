@@ -1,7 +1,6 @@
 package services
 
 import (
-	"encoding/json"
 	"io/ioutil"
 	"net/http"
 
@@ -111,7 +110,7 @@ func (c *RestOperations) GetParam(req *http.Request, name string) string {
 	return param
 }
 
-// DecodeBody methods helps decode body
+// DecodeBody methods helps decode body using codec selected by request Content-Type (JSON by default)
 //   - req   	- incoming request
 //   - target  	- pointer on target variable for decode
 // Returns error
@@ -122,7 +121,7 @@ func (c *RestOperations) DecodeBody(req *http.Request, target interface{}) error
 		return err
 	}
 	defer req.Body.Close()
-	err = json.Unmarshal(bytes, target)
+	err = HttpCodecs.ForRequest(req).Decode(bytes, target)
 	if err != nil {
 		return err
 	}
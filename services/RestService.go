@@ -1,7 +1,7 @@
 package services
 
 import (
-	"encoding/json"
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -247,6 +247,26 @@ func (c *RestService) Instrument(correlationId string, name string) *InstrumentT
 		c.Logger, c.Counters, counterTiming, traceTiming)
 }
 
+// InstrumentWithContext method are adds instrumentation to log calls and measure call time
+// and starts a child span of the trace carried by the context, i.e. the trace of the incoming request.
+// Parameters:
+//   - ctx               context.Context, i.e. the request context.
+//   - correlationId     (optional) transaction id to trace execution through call chain.
+//   - name              a method name.
+//
+// Returns a context that carries the started span and Timing object to end the time measurement.
+func (c *RestService) InstrumentWithContext(ctx context.Context, correlationId string,
+	name string) (context.Context, *InstrumentTiming) {
+	ctx, span := StartSpan(ctx)
+	c.Logger.Trace(correlationId, "Executing %s method in trace %s span %s", name, span.TraceId, span.SpanId)
+	c.Counters.IncrementOne(name + ".exec_count")
+	counterTiming := c.Counters.BeginTiming(name + ".exec_time")
+	traceTiming := c.Tracer.BeginTrace(correlationId, name, "")
+	timing := NewInstrumentTiming(correlationId, name, "exec",
+		c.Logger, c.Counters, counterTiming, traceTiming)
+	return ctx, timing.WithSpan(span)
+}
+
 // InstrumentError method are adds instrumentation to error handling.
 // Parameters:
 //   - correlationId  string  (optional) transaction id to trace execution through call chain.
@@ -420,6 +440,29 @@ func (c *RestService) RegisterRoute(method string, route string, schema *cvalid.
 	c.Endpoint.RegisterRoute(method, route, schema, action)
 }
 
+// RegisterRouteWithOptions method are registers a route in HTTP endpoint
+// with settings that override endpoint defaults.
+// When the endpoint does not implement IRouteOptionsEndpoint
+// the route is registered with default settings.
+// Parameters:
+//   - method        HTTP method: "get", "head", "post", "put", "delete"
+//   - route         a command route. Base route will be added to this route
+//   - schema        a validation schema to validate received parameters.
+//   - options       (optional) route settings like body size limits.
+//   - action        an action function that is called when operation is invoked.
+func (c *RestService) RegisterRouteWithOptions(method string, route string, schema *cvalid.Schema,
+	options *RouteOptions, action func(res http.ResponseWriter, req *http.Request)) {
+	if c.Endpoint == nil {
+		return
+	}
+	route = c.appendBaseRoute(route)
+	if endpoint, ok := c.Endpoint.(IRouteOptionsEndpoint); ok {
+		endpoint.RegisterRouteWithOptions(method, route, schema, options, action)
+		return
+	}
+	c.Endpoint.RegisterRoute(method, route, schema, action)
+}
+
 // RegisterRouteWithAuth method are registers a route with authorization in HTTP endpoint.
 // Parameters:
 //   - method        HTTP method: "get", "head", "post", "put", "delete"
@@ -445,6 +488,32 @@ func (c *RestService) RegisterRouteWithAuth(method string, route string, schema
 		}, action)
 }
 
+// RegisterRouteWithAuthAndOptions method are registers a route with authorization in HTTP endpoint
+// with settings that override endpoint defaults. Route limits are applied after the authorization.
+// When the endpoint does not implement IRouteOptionsEndpoint
+// the route is registered with default settings.
+// Parameters:
+//   - method        HTTP method: "get", "head", "post", "put", "delete"
+//   - route         a command route. Base route will be added to this route
+//   - schema        a validation schema to validate received parameters.
+//   - options       (optional) route settings like rate limits.
+//   - authorize     an authorization interceptor
+//   - action        an action function that is called when operation is invoked.
+func (c *RestService) RegisterRouteWithAuthAndOptions(method string, route string, schema *cvalid.Schema,
+	options *RouteOptions, authorize func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc),
+	action func(res http.ResponseWriter, req *http.Request)) {
+	if c.Endpoint == nil {
+		return
+	}
+	endpoint, ok := c.Endpoint.(IRouteOptionsEndpoint)
+	if !ok {
+		c.RegisterRouteWithAuth(method, route, schema, authorize, action)
+		return
+	}
+	route = c.appendBaseRoute(route)
+	endpoint.RegisterRouteWithAuthAndOptions(method, route, schema, options, authorize, action)
+}
+
 // RegisterInterceptor method are registers a middleware for a given route in HTTP endpoint.
 // Parameters:
 //   - route         a command route. Base route will be added to this route
@@ -474,7 +543,7 @@ func (c *RestService) GetParam(req *http.Request, name string) string {
 	return param
 }
 
-// DecodeBody methods helps decode body
+// DecodeBody methods helps decode body using codec selected by request Content-Type (JSON by default)
 //   - req   	- incoming request
 //   - target  	- pointer on target variable for decode
 //
@@ -486,7 +555,7 @@ func (c *RestService) DecodeBody(req *http.Request, target interface{}) error {
 		return err
 	}
 	defer req.Body.Close()
-	err = json.Unmarshal(bytes, target)
+	err = HttpCodecs.ForRequest(req).Decode(bytes, target)
 	if err != nil {
 		return err
 	}
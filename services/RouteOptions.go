@@ -0,0 +1,31 @@
+package services
+
+import "time"
+
+/*
+RouteOptions defines per-route settings that override defaults of HTTP endpoint.
+Zero values mean that endpoint defaults are used.
+
+Example:
+
+	service.RegisterRouteWithOptions("post", "/upload", nil,
+		&RouteOptions{FileMaxSize: 1024 * 1024 * 1024},
+		service.upload)
+*/
+type RouteOptions struct {
+	// Maximum size of request body in bytes, -1 to disable the limit.
+	RequestMaxSize int64
+	// Maximum size of multipart request body in bytes, -1 to disable the limit.
+	FileMaxSize int64
+	// Maximum number of requests per client within RateLimitWindow, 0 for no route limit.
+	// The route limit is applied in addition to the endpoint-wide rate limit.
+	RateLimit int
+	// Rate limit window, 0 to use the window configured for the endpoint.
+	RateLimitWindow time.Duration
+	// Maximum number of requests to the route processed at the same time, 0 for no route limit.
+	// The route limit is applied in addition to the endpoint-wide limit.
+	MaxInFlight int
+	// Maximum time to process the request, 0 to use the endpoint request timeout, -1 to disable it.
+	// On timeout the request context is cancelled and 504 error is returned.
+	Timeout time.Duration
+}
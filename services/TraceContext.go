@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceParentHeader is the W3C Trace Context header with trace id, parent span id and flags
+	TraceParentHeader = "traceparent"
+	// TraceStateHeader is the W3C Trace Context header with vendor-specific trace information
+	TraceStateHeader = "tracestate"
+)
+
+const traceSampledFlag = 0x01
+
+/*
+SpanContext identifies a span of a distributed trace propagated
+between services in W3C Trace Context headers (https://www.w3.org/TR/trace-context/).
+
+HttpEndpoint parses traceparent and tracestate headers of incoming requests
+and puts a server span into the request context. RestClient sends the span
+from the call context in the headers of outgoing requests, so traces continue across hops.
+Use InstrumentWithContext of RestService and RestClient to create child spans for operations.
+
+Example:
+
+	span := services.SpanFromContext(req.Context())
+	if span != nil {
+		fmt.Println("Trace id: " + span.TraceId)
+	}
+*/
+type SpanContext struct {
+	// Trace id as 32 lowercase hex characters.
+	TraceId string
+	// Span id as 16 lowercase hex characters.
+	SpanId string
+	// Id of the parent span or empty string for root spans.
+	ParentSpanId string
+	// Trace flags, i.e. sampled flag.
+	Flags byte
+	// Vendor-specific trace information from tracestate header.
+	TraceState string
+}
+
+type spanContextKey struct{}
+
+// NewSpanContext creates a root span of a new sampled trace.
+// Returns: *SpanContext
+func NewSpanContext() *SpanContext {
+	return &SpanContext{
+		TraceId: newTraceId(16),
+		SpanId:  newTraceId(8),
+		Flags:   traceSampledFlag,
+	}
+}
+
+// ParseTraceParent parses traceparent and tracestate header values into a span context.
+// Parameters:
+//   - traceParent  string  a value of traceparent header.
+//   - traceState   string  a value of tracestate header (optional).
+//
+// Returns: *SpanContext, error
+// the parsed span context or error if traceparent is invalid.
+func ParseTraceParent(traceParent string, traceState string) (*SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 {
+		return nil, errors.New("traceparent must contain version, trace id, parent id and flags")
+	}
+	version := parts[0]
+	if !isTraceHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return nil, errors.New("traceparent version is not supported")
+	}
+	if !isTraceHex(parts[1], 32) || parts[1] == strings.Repeat("0", 32) {
+		return nil, errors.New("traceparent trace id is invalid")
+	}
+	if !isTraceHex(parts[2], 16) || parts[2] == strings.Repeat("0", 16) {
+		return nil, errors.New("traceparent parent id is invalid")
+	}
+	if !isTraceHex(parts[3], 2) {
+		return nil, errors.New("traceparent flags are invalid")
+	}
+	flags, _ := hex.DecodeString(parts[3])
+
+	return &SpanContext{
+		TraceId:    parts[1],
+		SpanId:     parts[2],
+		Flags:      flags[0],
+		TraceState: strings.TrimSpace(traceState),
+	}, nil
+}
+
+// NewChild creates a child span in the same trace.
+// Returns: *SpanContext
+func (c *SpanContext) NewChild() *SpanContext {
+	return &SpanContext{
+		TraceId:      c.TraceId,
+		SpanId:       newTraceId(8),
+		ParentSpanId: c.SpanId,
+		Flags:        c.Flags,
+		TraceState:   c.TraceState,
+	}
+}
+
+// IsSampled checks if the trace is sampled by the caller.
+func (c *SpanContext) IsSampled() bool {
+	return c.Flags&traceSampledFlag != 0
+}
+
+// TraceParent formats the span as a value of traceparent header.
+// Returns: string
+func (c *SpanContext) TraceParent() string {
+	return "00-" + c.TraceId + "-" + c.SpanId + "-" + hex.EncodeToString([]byte{c.Flags})
+}
+
+// ContextWithSpan returns a copy of the context that carries the span.
+// Parameters:
+//   - ctx   context.Context  a parent context.
+//   - span  *SpanContext     a span to carry.
+//
+// Returns: context.Context
+func ContextWithSpan(ctx context.Context, span *SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext gets the span carried by the context.
+// Parameters:
+//   - ctx   context.Context  a context.
+//
+// Returns: *SpanContext or nil if the context carries no span.
+func SpanFromContext(ctx context.Context) *SpanContext {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanContextKey{}).(*SpanContext)
+	return span
+}
+
+// SpanFromRequest gets the span of the request served by HTTP endpoint.
+// Parameters:
+//   - req   *http.Request  an HTTP request.
+//
+// Returns: *SpanContext or nil if the request carries no span.
+func SpanFromRequest(req *http.Request) *SpanContext {
+	return SpanFromContext(req.Context())
+}
+
+// Starts a server span for incoming requests that continues the trace of the caller
+func (c *HttpEndpoint) traceRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var span *SpanContext
+		parent, err := ParseTraceParent(r.Header.Get(TraceParentHeader),
+			strings.Join(r.Header.Values(TraceStateHeader), ","))
+		if err == nil {
+			span = parent.NewChild()
+		} else {
+			span = NewSpanContext()
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithSpan(r.Context(), span)))
+	})
+}
+
+// StartSpan starts a child span of the span carried by the context or a root span of a new trace.
+// Parameters:
+//   - ctx   context.Context  a parent context.
+//
+// Returns: context.Context, *SpanContext
+// a context that carries the started span and the span.
+func StartSpan(ctx context.Context) (context.Context, *SpanContext) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var span *SpanContext
+	if parent := SpanFromContext(ctx); parent != nil {
+		span = parent.NewChild()
+	} else {
+		span = NewSpanContext()
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+func newTraceId(size int) string {
+	id := make([]byte, size)
+	for {
+		rand.Read(id)
+		for _, b := range id {
+			if b != 0 {
+				return hex.EncodeToString(id)
+			}
+		}
+	}
+}
+
+func isTraceHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, ch := range value {
+		if !((ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f')) {
+			return false
+		}
+	}
+	return true
+}
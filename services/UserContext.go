@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"net/http"
+)
+
+type userIdKey struct{}
+
+// ContextWithUserId returns a copy of the context that carries id of the authenticated user.
+// It is set by auth managers, so access log, maintenance and rate limits can identify users
+// without depending on the way they were authenticated.
+// Parameters:
+//   - ctx     context.Context  a parent context.
+//   - userId  string           id of the authenticated user.
+//
+// Returns: context.Context
+func ContextWithUserId(ctx context.Context, userId string) context.Context {
+	// Report the user to the access log of the endpoint
+	if record, ok := ctx.Value(httpRequestRecordKey{}).(*httpRequestRecord); ok {
+		record.userId = userId
+	}
+	return context.WithValue(ctx, userIdKey{}, userId)
+}
+
+// UserIdFromContext gets id of the authenticated user carried by the context.
+// Parameters:
+//   - ctx  context.Context  a context.
+//
+// Returns: string
+// the user id or empty string when the request is not authenticated.
+func UserIdFromContext(ctx context.Context) string {
+	userId, _ := ctx.Value(userIdKey{}).(string)
+	return userId
+}
+
+// UserIdFromRequest gets id of the authenticated user of the request.
+// Parameters:
+//   - req  *http.Request  an HTTP request.
+//
+// Returns: string
+// the user id or empty string when the request is not authenticated.
+func UserIdFromRequest(req *http.Request) string {
+	return UserIdFromContext(req.Context())
+}
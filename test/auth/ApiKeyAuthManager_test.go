@@ -0,0 +1,129 @@
+package test_auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	cauth "github.com/pip-services3-go/pip-services3-components-go/auth"
+	"github.com/pip-services3-go/pip-services3-rpc-go/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func callApiKeyAuth(manager *auth.ApiKeyAuthManager, interceptor func(http.ResponseWriter, *http.Request, http.HandlerFunc),
+	url string, key string) (int, *auth.Principal) {
+	req := httptest.NewRequest("GET", url, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	res := httptest.NewRecorder()
+	var principal *auth.Principal
+	interceptor(res, req, func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.UserFromRequest(r)
+		roleAuth := &auth.RoleAuthManager{}
+		roleAuth.UserInRoles([]string{"admin", "write"})(w, r, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(204)
+		})
+	})
+	return res.Code, principal
+}
+
+func TestApiKeyAuthManager(t *testing.T) {
+	manager := auth.NewApiKeyAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"query_param", "api_key",
+		"keys.billing.keys", "key1, key2",
+		"keys.billing.scopes", "read,write",
+		"keys.reports.keys", "key3",
+		"keys.reports.roles", "reader",
+	))
+
+	// Both active keys are accepted during rotation
+	for _, key := range []string{"key1", "key2"} {
+		code, principal := callApiKeyAuth(manager, manager.Authenticate(), "/", key)
+		assert.Equal(t, 204, code)
+		assert.Equal(t, "billing", principal.Id)
+		assert.True(t, principal.HasRole("write"))
+		assert.Equal(t, 2, principal.Properties.GetAsArray("scopes").Len())
+	}
+
+	code, principal := callApiKeyAuth(manager, manager.Authenticate(), "/?api_key=key3", "")
+	assert.Equal(t, 403, code)
+	assert.Equal(t, "reports", principal.Id)
+
+	code, _ = callApiKeyAuth(manager, manager.Authenticate(), "/", "unknown")
+	assert.Equal(t, 401, code)
+
+	// Anonymous requests are rejected by role checks or Signed
+	code, _ = callApiKeyAuth(manager, manager.Authenticate(), "/", "")
+	assert.Equal(t, 401, code)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "")
+	assert.Equal(t, 401, code)
+}
+
+func TestApiKeyAuthManagerStores(t *testing.T) {
+	store := auth.NewMemoryApiKeyStore()
+	store.AddKey("old", &auth.ApiKey{Id: "billing", Roles: []string{"admin"}})
+	manager := auth.NewApiKeyAuthManager()
+	manager.SetStore(store)
+
+	code, _ := callApiKeyAuth(manager, manager.Signed(), "/", "old")
+	assert.Equal(t, 204, code)
+
+	store.AddKey("new", &auth.ApiKey{Id: "billing", Roles: []string{"admin"}})
+	store.RemoveKey("old")
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "old")
+	assert.Equal(t, 401, code)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "new")
+	assert.Equal(t, 204, code)
+
+	// Keys in credential store
+	credentials := cauth.NewEmptyMemoryCredentialStore()
+	credentials.Store("", "secret-key", cauth.NewCredentialParamsFromTuples(
+		"access_id", "reports",
+		"roles", "admin",
+	))
+	manager = auth.NewApiKeyAuthManager()
+	manager.SetReferences(cref.NewReferencesFromTuples(
+		cref.NewDescriptor("pip-services", "credential-store", "memory", "default", "1.0"), credentials,
+	))
+	code, principal := callApiKeyAuth(manager, manager.Signed(), "/", "secret-key")
+	assert.Equal(t, 204, code)
+	assert.Equal(t, "reports", principal.Id)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "other-key")
+	assert.Equal(t, 401, code)
+
+	// Custom store and configured keys are not replaced by credential store
+	references := cref.NewReferencesFromTuples(
+		cref.NewDescriptor("pip-services", "credential-store", "memory", "default", "1.0"), credentials,
+	)
+	manager = auth.NewApiKeyAuthManager()
+	manager.SetStore(store)
+	manager.SetReferences(references)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "new")
+	assert.Equal(t, 204, code)
+
+	manager = auth.NewApiKeyAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"keys.billing.keys", "key1",
+		"keys.billing.roles", "admin",
+	))
+	manager.SetReferences(references)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "key1")
+	assert.Equal(t, 204, code)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "secret-key")
+	assert.Equal(t, 401, code)
+
+	// Unless credential store is requested explicitly
+	manager = auth.NewApiKeyAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"keys.billing.keys", "key1",
+		"keys.billing.roles", "admin",
+		"use_credential_store", true,
+	))
+	manager.SetReferences(references)
+	code, _ = callApiKeyAuth(manager, manager.Signed(), "/", "secret-key")
+	assert.Equal(t, 204, code)
+}
@@ -0,0 +1,155 @@
+package test_auth
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	"github.com/pip-services3-go/pip-services3-rpc-go/auth"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func callBasicAuth(manager *auth.BasicAuthManager, url string, authorization string) (*httptest.ResponseRecorder, *auth.Principal) {
+	req := httptest.NewRequest("GET", url, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	res := httptest.NewRecorder()
+	var principal *auth.Principal
+	manager.Authenticate()(res, req, func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.UserFromRequest(r)
+		manager.Signed()(w, r, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(204)
+		})
+	})
+	return res, principal
+}
+
+func md5Hex(value string) string {
+	hash := md5.Sum([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestBasicAuthManager(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("pass123"), bcrypt.MinCost)
+	manager := auth.NewBasicAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"realm", "test",
+		"users.admin.id", "1",
+		"users.admin.password_hash", string(hash),
+		"users.admin.roles", "admin,user",
+	))
+
+	// "admin:pass123"
+	res, principal := callBasicAuth(manager, "/", "Basic YWRtaW46cGFzczEyMw==")
+	assert.Equal(t, 204, res.Code)
+	assert.Equal(t, "1", principal.Id)
+	assert.True(t, principal.HasRole("admin"))
+	assert.Equal(t, "admin", principal.Properties.GetAsString("login"))
+
+	// "admin:wrong"
+	res, _ = callBasicAuth(manager, "/", "Basic YWRtaW46d3Jvbmc=")
+	assert.Equal(t, 401, res.Code)
+	assert.Contains(t, res.Body.String(), auth.InvalidCredentialsErrorCode)
+	assert.Equal(t, "Basic realm=\"test\", charset=\"UTF-8\"", res.Header().Get("WWW-Authenticate"))
+
+	// "guest:pass123"
+	res, _ = callBasicAuth(manager, "/", "Basic Z3Vlc3Q6cGFzczEyMw==")
+	assert.Equal(t, 401, res.Code)
+
+	res, _ = callBasicAuth(manager, "/", "Basic !!!")
+	assert.Equal(t, 401, res.Code)
+
+	// Anonymous requests are challenged by Signed
+	res, _ = callBasicAuth(manager, "/", "")
+	assert.Equal(t, 401, res.Code)
+	assert.Contains(t, res.Body.String(), "NOT_SIGNED")
+	assert.NotEmpty(t, res.Header().Get("WWW-Authenticate"))
+
+	// Other schemes are left to other managers
+	res, _ = callBasicAuth(manager, "/", "Bearer token")
+	assert.Equal(t, 401, res.Code)
+	assert.Contains(t, res.Body.String(), "NOT_SIGNED")
+
+	// Zero value keeps legacy behavior
+	legacy := &auth.BasicAuthManager{}
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	legacy.Signed()(rec, req, nil)
+	assert.Equal(t, 401, rec.Code)
+	assert.Empty(t, rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestDigestAuthManager(t *testing.T) {
+	manager := auth.NewBasicAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"realm", "test",
+		"digest", true,
+		"users.admin.digest_ha1", auth.DigestHA1("admin", "test", "pass123"),
+	))
+	assert.Equal(t, md5Hex("admin:test:pass123"), auth.DigestHA1("admin", "test", "pass123"))
+
+	res, _ := callBasicAuth(manager, "/dummies?id=1", "")
+	assert.Equal(t, 401, res.Code)
+	challenges := res.Header().Values("WWW-Authenticate")
+	assert.Len(t, challenges, 2)
+	nonce := regexp.MustCompile(`nonce="([^"]+)"`).FindStringSubmatch(challenges[1])[1]
+
+	digestNc := func(password string, nonce string, nc string) string {
+		ha1 := md5Hex("admin:test:" + password)
+		ha2 := md5Hex("GET:/dummies?id=1")
+		response := md5Hex(ha1 + ":" + nonce + ":" + nc + ":abc:auth:" + ha2)
+		return "Digest username=\"admin\", realm=\"test\", nonce=\"" + nonce + "\", uri=\"/dummies?id=1\", " +
+			"qop=auth, nc=" + nc + ", cnonce=\"abc\", algorithm=MD5, response=\"" + response + "\""
+	}
+	digest := func(password string, nonce string) string {
+		return digestNc(password, nonce, "00000001")
+	}
+
+	res, principal := callBasicAuth(manager, "/dummies?id=1", digest("pass123", nonce))
+	assert.Equal(t, 204, res.Code)
+	assert.Equal(t, "admin", principal.Id)
+
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digest("wrong", nonce))
+	assert.Equal(t, 401, res.Code)
+
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digest("pass123", "forged"))
+	assert.Equal(t, 401, res.Code)
+
+	// Uri must match the request
+	res, _ = callBasicAuth(manager, "/dummies?id=2", digest("pass123", nonce))
+	assert.Equal(t, 401, res.Code)
+
+	// Replayed requests are rejected, nonce count must increase
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digest("pass123", nonce))
+	assert.Equal(t, 401, res.Code)
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digestNc("pass123", nonce, "00000002"))
+	assert.Equal(t, 204, res.Code)
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digestNc("pass123", nonce, "00000002"))
+	assert.Equal(t, 401, res.Code)
+
+	// Clients challenged within the same second get different nonces
+	challenge := func() (string, []byte) {
+		res, _ := callBasicAuth(manager, "/dummies?id=1", "")
+		nonce := regexp.MustCompile(`nonce="([^"]+)"`).FindStringSubmatch(res.Header().Values("WWW-Authenticate")[1])[1]
+		data, _ := base64.RawURLEncoding.DecodeString(nonce)
+		return nonce, data[:8]
+	}
+	nonce1, issued1 := challenge()
+	nonce2, issued2 := challenge()
+	for string(issued1) != string(issued2) {
+		nonce1, issued1 = nonce2, issued2
+		nonce2, issued2 = challenge()
+	}
+	assert.NotEqual(t, nonce1, nonce2)
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digest("pass123", nonce1))
+	assert.Equal(t, 204, res.Code)
+	res, _ = callBasicAuth(manager, "/dummies?id=1", digest("pass123", nonce2))
+	assert.Equal(t, 204, res.Code)
+}
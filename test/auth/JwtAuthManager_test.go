@@ -0,0 +1,343 @@
+package test_auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	"github.com/pip-services3-go/pip-services3-rpc-go/auth"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeSegment(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signToken(alg string, kid string, key interface{}, claims map[string]interface{}) string {
+	header := map[string]interface{}{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	signed := encodeSegment(header) + "." + encodeSegment(claims)
+	hash := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, hash[:])
+	case "ES256":
+		r, s, _ := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), hash[:])
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func assertTokenError(t *testing.T, code string, err error) {
+	appErr, ok := err.(*cerr.ApplicationError)
+	if assert.True(t, ok, "expected application error") {
+		assert.Equal(t, code, appErr.Code)
+		assert.Equal(t, 401, appErr.Status)
+	}
+}
+
+func TestJwtAuthManagerHS256(t *testing.T) {
+	manager := auth.NewJwtAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"secret", "secret",
+		"issuer", "test-issuer",
+		"audience", "test-service",
+		"clock_skew", 1000,
+	))
+	now := time.Now().Unix()
+	claims := map[string]interface{}{
+		"sub": "1", "iss": "test-issuer", "aud": []string{"other", "test-service"}, "exp": now + 60,
+	}
+
+	result, err := manager.ValidateToken("", signToken("HS256", "", []byte("secret"), claims))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", result["sub"])
+
+	_, err = manager.ValidateToken("", signToken("HS256", "", []byte("wrong"), claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+
+	_, err = manager.ValidateToken("", "not.a.token")
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+
+	_, err = manager.ValidateToken("", signToken("none", "", []byte("secret"), claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+
+	claims["exp"] = now - 10
+	_, err = manager.ValidateToken("", signToken("HS256", "", []byte("secret"), claims))
+	assertTokenError(t, auth.TokenExpiredErrorCode, err)
+
+	claims["exp"] = now + 60
+	claims["nbf"] = now + 30
+	_, err = manager.ValidateToken("", signToken("HS256", "", []byte("secret"), claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+
+	delete(claims, "nbf")
+	claims["iss"] = "other-issuer"
+	_, err = manager.ValidateToken("", signToken("HS256", "", []byte("secret"), claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+
+	claims["iss"] = "test-issuer"
+	claims["aud"] = "other"
+	_, err = manager.ValidateToken("", signToken("HS256", "", []byte("secret"), claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+}
+
+func TestJwtAuthManagerPublicKeys(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]interface{}{
+			{
+				"kty": "RSA", "kid": "rsa1", "use": "sig",
+				"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec1", "crv": "P-256",
+				"x": base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+				"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	defer server.Close()
+
+	manager := auth.NewJwtAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"jwks.uri", server.URL,
+		"algorithms", "RS256,ES256",
+	))
+	claims := map[string]interface{}{"sub": "1", "exp": time.Now().Unix() + 60}
+
+	_, err := manager.ValidateToken("", signToken("RS256", "rsa1", rsaKey, claims))
+	assert.Nil(t, err)
+	_, err = manager.ValidateToken("", signToken("ES256", "ec1", ecKey, claims))
+	assert.Nil(t, err)
+
+	// Key type must match the algorithm
+	_, err = manager.ValidateToken("", signToken("ES256", "rsa1", ecKey, claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+	_, err = manager.ValidateToken("", signToken("RS256", "unknown", rsaKey, claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+	_, err = manager.ValidateToken("", signToken("HS256", "", []byte("secret"), claims))
+	assertTokenError(t, auth.InvalidTokenErrorCode, err)
+
+	// Public key in PEM format
+	der, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	manager = auth.NewJwtAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"public_key", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	))
+	_, err = manager.ValidateToken("", signToken("RS256", "", rsaKey, claims))
+	assert.Nil(t, err)
+}
+
+type jwtRoutes struct {
+	endpoint *services.HttpEndpoint
+	manager  *auth.JwtAuthManager
+}
+
+func (c *jwtRoutes) Register() {
+	roleAuth := &auth.RoleAuthManager{}
+	c.endpoint.RegisterInterceptor("/users", c.manager.Authenticate())
+	c.endpoint.RegisterRouteWithAuth("get", "/users/me", nil, roleAuth.Admin(), func(res http.ResponseWriter, req *http.Request) {
+		user, _ := auth.UserFromRequest(req)
+		services.HttpResponseSender.SendResult(res, req, map[string]interface{}{
+			"user_id": user.Id,
+			"name":    user.Properties.GetAsString("name"),
+		}, nil)
+	})
+}
+
+func TestJwtAuthManagerInterceptor(t *testing.T) {
+	manager := auth.NewJwtAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples("secret", "secret"))
+
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+	endpoint.Register(&jwtRoutes{endpoint: endpoint, manager: manager})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr() + "/users/me"
+
+	call := func(token string) (*http.Response, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", url, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+
+	token := signToken("HS256", "", []byte("secret"), map[string]interface{}{
+		"sub": "123", "name": "Admin", "roles": "user admin", "exp": time.Now().Unix() + 60,
+	})
+	resp, body := call(token)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "123", body["user_id"])
+	assert.Equal(t, "Admin", body["name"])
+
+	token = signToken("HS256", "", []byte("secret"), map[string]interface{}{
+		"sub": "456", "roles": []string{"user"},
+	})
+	resp, body = call(token)
+	assert.Equal(t, 403, resp.StatusCode)
+	assert.Equal(t, "NOT_IN_ROLE", body["code"])
+
+	resp, body = call("invalid")
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, auth.InvalidTokenErrorCode, body["code"])
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "invalid_token")
+
+	// Token values are not echoed into the challenge
+	resp, _ = call(encodeSegment(map[string]interface{}{"alg": "x\", realm=\"evil"}) + "." +
+		encodeSegment(map[string]interface{}{"sub": "123"}) + ".c2ln")
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "Bearer error=\"invalid_token\", error_description=\"The access token is invalid\"",
+		resp.Header.Get("WWW-Authenticate"))
+
+	resp, body = call("")
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "NOT_SIGNED", body["code"])
+
+	// Other authorization schemes are left to other auth managers
+	req, _ := http.NewRequest("GET", url, nil)
+	req.SetBasicAuth("admin", "password")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "NOT_SIGNED", body["code"])
+}
+
+func TestJwtAuthManagerLegacyContext(t *testing.T) {
+	manager := auth.NewJwtAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"secret", "secret",
+		"options.legacy_context", true,
+	))
+	token := signToken("HS256", "", []byte("secret"), map[string]interface{}{
+		"sub": "123", "name": "Admin", "roles": "user admin",
+	})
+
+	req := httptest.NewRequest("GET", "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	var user cdata.AnyValueMap
+	var userId interface{}
+	manager.Authenticate()(httptest.NewRecorder(), req, func(res http.ResponseWriter, req *http.Request) {
+		user, _ = req.Context().Value("user").(cdata.AnyValueMap)
+		userId = req.Context().Value("user_id")
+	})
+
+	// Claims are mapped into the legacy user map
+	assert.Equal(t, "123", userId)
+	assert.Equal(t, "123", user.GetAsString("id"))
+	assert.Equal(t, "Admin", user.GetAsString("name"))
+	assert.Equal(t, 2, user.GetAsArray("roles").Len())
+}
+
+func TestJwtKeySetRefresh(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks := map[string]interface{}{
+		"keys": []map[string]interface{}{
+			{
+				"kty": "RSA", "kid": "rsa1",
+				"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+		},
+	}
+	var loads int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold all reloads after the first one
+		if atomic.AddInt32(&loads, 1) > 1 {
+			<-release
+		}
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	keySet := auth.NewJwtKeySet(server.URL, 50*time.Millisecond)
+	key, err := keySet.GetKey("rsa1")
+	assert.Nil(t, err)
+	assert.NotNil(t, key)
+
+	time.Sleep(100 * time.Millisecond)
+	go keySet.GetKey("rsa1")
+	for atomic.LoadInt32(&loads) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Cached keys are used while the key set is reloaded
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		key, err = keySet.GetKey("rsa1")
+		assert.Nil(t, err)
+		assert.NotNil(t, key)
+	}
+	assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+}
+
+func TestJwtKeySetLoadFailure(t *testing.T) {
+	var loads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&loads, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	keySet := auth.NewJwtKeySet(server.URL, 0)
+	for i := 0; i < 5; i++ {
+		key, err := keySet.GetKey("rsa1")
+		assert.NotNil(t, err)
+		assert.Nil(t, key)
+	}
+
+	// Failed load is not repeated on every request
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
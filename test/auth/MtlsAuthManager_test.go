@@ -0,0 +1,104 @@
+package test_auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	"github.com/pip-services3-go/pip-services3-rpc-go/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func createClientCertificate(t *testing.T, subject pkix.Name, dnsNames []string, uris []string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      subject,
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, value := range uris {
+		uri, _ := url.Parse(value)
+		template.URIs = append(template.URIs, uri)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return cert
+}
+
+func callMtlsAuth(interceptor func(http.ResponseWriter, *http.Request, http.HandlerFunc),
+	cert *x509.Certificate, verified bool) (int, *auth.Principal) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if cert != nil {
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		if verified {
+			req.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+		}
+	}
+	res := httptest.NewRecorder()
+	var principal *auth.Principal
+	interceptor(res, req, func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = auth.UserFromRequest(r)
+		roleAuth := &auth.RoleAuthManager{}
+		roleAuth.UserInRole("billing")(w, r, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(204)
+		})
+	})
+	return res.Code, principal
+}
+
+func TestMtlsAuthManager(t *testing.T) {
+	manager := auth.NewMtlsAuthManager()
+	manager.Configure(cconf.NewConfigParamsFromTuples(
+		"roles.billing", "spiffe:spiffe://example.org/ns/prod/sa/billing-*, ou:payments",
+		"roles.admin", "cn:ops",
+	))
+
+	cert := createClientCertificate(t, pkix.Name{CommonName: "billing"}, []string{"billing.svc.local"},
+		[]string{"spiffe://example.org/ns/prod/sa/billing-api"})
+	code, principal := callMtlsAuth(manager.Authenticate(), cert, true)
+	assert.Equal(t, 204, code)
+	assert.Equal(t, "spiffe://example.org/ns/prod/sa/billing-api", principal.Id)
+	assert.Equal(t, []string{"billing"}, principal.Roles)
+	assert.Equal(t, "billing", principal.Properties.GetAsString("cn"))
+	assert.Equal(t, "spiffe://example.org/ns/prod/sa/billing-api", principal.Properties.GetAsString("spiffe_id"))
+	assert.Equal(t, 1, principal.Properties.GetAsArray("dns_names").Len())
+	assert.Equal(t, "42", principal.Properties.GetAsString("serial_number"))
+
+	// Roles are mapped from subject fields, id falls back to common name
+	cert = createClientCertificate(t, pkix.Name{CommonName: "ops", OrganizationalUnit: []string{"payments"}}, nil, nil)
+	code, principal = callMtlsAuth(manager.Authenticate(), cert, true)
+	assert.Equal(t, 204, code)
+	assert.Equal(t, "ops", principal.Id)
+	assert.True(t, principal.HasRole("admin"))
+	assert.True(t, principal.HasRole("billing"))
+
+	cert = createClientCertificate(t, pkix.Name{CommonName: "reports"}, nil,
+		[]string{"spiffe://example.org/ns/prod/sa/reports"})
+	code, _ = callMtlsAuth(manager.Authenticate(), cert, true)
+	assert.Equal(t, 403, code)
+
+	// Unverified certificates are rejected
+	code, _ = callMtlsAuth(manager.Authenticate(), cert, false)
+	assert.Equal(t, 401, code)
+
+	code, _ = callMtlsAuth(manager.Authenticate(), nil, false)
+	assert.Equal(t, 401, code)
+	code, _ = callMtlsAuth(manager.Signed(), nil, false)
+	assert.Equal(t, 401, code)
+}
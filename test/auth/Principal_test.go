@@ -0,0 +1,104 @@
+package test_auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	"github.com/pip-services3-go/pip-services3-rpc-go/auth"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalContext(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	_, ok := auth.UserFromRequest(req)
+	assert.False(t, ok)
+
+	principal := auth.NewPrincipal("1", []string{"user", "admin"},
+		cdata.NewAnyValueMapFromTuples("name", "Admin"))
+	req = req.WithContext(auth.WithUser(req.Context(), principal))
+
+	user, ok := auth.UserFromRequest(req)
+	assert.True(t, ok)
+	assert.Equal(t, principal, user)
+	assert.True(t, user.HasRole("admin"))
+	assert.False(t, user.HasRole("guest"))
+	assert.True(t, user.HasAnyRole([]string{"guest", "user"}))
+
+	assert.Equal(t, "1", services.UserIdFromRequest(req))
+
+	// Legacy values are not set by default
+	assert.Nil(t, req.Context().Value("user"))
+	assert.Nil(t, req.Context().Value("user_id"))
+
+	// Legacy values are set for existing handlers
+	req = req.WithContext(auth.WithLegacyUser(req.Context(), principal))
+	legacy, ok := req.Context().Value("user").(cdata.AnyValueMap)
+	assert.True(t, ok)
+	assert.Equal(t, "1", legacy.GetAsString("id"))
+	assert.Equal(t, "Admin", legacy.GetAsString("name"))
+	assert.Equal(t, 2, legacy.GetAsArray("roles").Len())
+	assert.Equal(t, "1", req.Context().Value("user_id"))
+}
+
+func TestPrincipalLegacyFallback(t *testing.T) {
+	legacy := cdata.NewAnyValueMapFromTuples(
+		"name", "User",
+		"roles", []interface{}{"user"},
+	)
+
+	ctx := context.WithValue(context.Background(), "user", *legacy)
+	ctx = context.WithValue(ctx, "user_id", "2")
+	user, ok := auth.UserFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "2", user.Id)
+	assert.Equal(t, []string{"user"}, user.Roles)
+	assert.Equal(t, "User", user.Properties.GetAsString("name"))
+
+	// Pointer maps with id inside are accepted too
+	legacy.Put("id", "3")
+	ctx = context.WithValue(context.Background(), "user", legacy)
+	user, ok = auth.UserFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "3", user.Id)
+}
+
+func TestAuthManagersWithPrincipal(t *testing.T) {
+	call := func(interceptor func(http.ResponseWriter, *http.Request, http.HandlerFunc),
+		principal *auth.Principal, vars map[string]string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		if principal != nil {
+			req = req.WithContext(auth.WithUser(req.Context(), principal))
+		}
+		if vars != nil {
+			req = mux.SetURLVars(req, vars)
+		}
+		res := httptest.NewRecorder()
+		interceptor(res, req, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(204)
+		})
+		return res.Code
+	}
+
+	basicAuth := &auth.BasicAuthManager{}
+	roleAuth := &auth.RoleAuthManager{}
+	ownerAuth := &auth.OwnerAuthManager{}
+	user := auth.NewPrincipal("1", []string{"user"}, nil)
+	admin := auth.NewPrincipal("2", []string{"admin"}, nil)
+
+	assert.Equal(t, 401, call(basicAuth.Signed(), nil, nil))
+	assert.Equal(t, 204, call(basicAuth.Signed(), user, nil))
+
+	assert.Equal(t, 401, call(roleAuth.Admin(), nil, nil))
+	assert.Equal(t, 403, call(roleAuth.Admin(), user, nil))
+	assert.Equal(t, 204, call(roleAuth.Admin(), admin, nil))
+
+	assert.Equal(t, 204, call(ownerAuth.Owner(""), user, map[string]string{"user_id": "1"}))
+	assert.Equal(t, 403, call(ownerAuth.Owner(""), admin, map[string]string{"user_id": "1"}))
+	assert.Equal(t, 204, call(ownerAuth.OwnerOrAdmin(""), admin, map[string]string{"user_id": "1"}))
+	assert.Equal(t, 403, call(ownerAuth.OwnerOrAdmin(""), user, map[string]string{"user_id": "2"}))
+}
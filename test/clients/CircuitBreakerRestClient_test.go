@@ -0,0 +1,62 @@
+package test_clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerRestClient(t *testing.T) {
+	var calls int32
+	var healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", server.URL,
+		"options.retries", 1,
+		"options.circuit_breaker.enabled", true,
+		"options.circuit_breaker.failure_threshold", 2,
+		"options.circuit_breaker.cool_down", 100,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Call(nil, "get", "/data", "123", nil, nil)
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, clients.CircuitOpen, client.CircuitBreaker.State())
+
+	// Open circuit fails fast
+	_, err = client.Call(nil, "get", "/data", "123", nil, nil)
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	assert.Equal(t, clients.CircuitOpenErrorCode, appErr.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Trial call closes the circuit after cool down
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(150 * time.Millisecond)
+	_, err = client.Call(nil, "get", "/data", "123", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, clients.CircuitClosed, client.CircuitBreaker.State())
+}
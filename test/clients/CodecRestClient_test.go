@@ -0,0 +1,46 @@
+package test_clients
+
+import (
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	tdata "github.com/pip-services3-go/pip-services3-rpc-go/test/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgpackCommandableHttpClient(t *testing.T) {
+	client := NewDummyCommandableHttpClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", DummyCommandableHttpServicePort,
+		"options.content_type", "application/msgpack",
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	created, err := clients.CallCommand[*tdata.Dummy](&client.CommandableHttpClient, "create_dummy", "123",
+		cdata.NewAnyValueMapFromTuples("dummy", tdata.Dummy{Key: "Msgpack", Content: "Msgpack content"}))
+	assert.Nil(t, err)
+	assert.NotNil(t, created)
+	assert.Equal(t, "Msgpack", created.Key)
+
+	dummy, err := client.GetDummyById("123", created.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Msgpack content", dummy.Content)
+
+	_, err = client.DeleteDummy("123", created.Id)
+	assert.Nil(t, err)
+
+	err = client.CheckErrorPropagation("test_error_propagation")
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	assert.Equal(t, "NotFound", appErr.Category)
+	assert.Equal(t, 404, appErr.Status)
+}
@@ -0,0 +1,53 @@
+package test_clients
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionRestClient(t *testing.T) {
+	content := strings.Repeat("compressed content ", 1000)
+	encodings := make(chan string, 10)
+
+	compression := services.NewHttpCompression()
+	compression.Enabled = true
+	server := httptest.NewServer(compression.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings <- r.Header.Get("Accept-Encoding")
+		services.HttpResponseSender.SendResult(w, r, map[string]string{"content": content}, nil)
+	})))
+	defer server.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", server.URL,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	result, err := client.Call(reflect.TypeOf(map[string]string{}), "get", "/content", "123", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, content, (*result.(*map[string]string))["content"])
+	assert.Equal(t, "br, gzip, deflate", <-encodings)
+
+	resp, err := client.CallStream(context.Background(), "get", "/content", "123", nil, nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Header.Get("Content-Encoding"))
+	data, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Close())
+	assert.Contains(t, string(data), content)
+	<-encodings
+}
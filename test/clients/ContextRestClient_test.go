@@ -0,0 +1,57 @@
+package test_clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextRestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", server.URL,
+		"options.retries", 3,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.CallWithContext(ctx, nil, "get", "/slow", "123", nil, nil)
+	assert.NotNil(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	assert.Equal(t, clients.TimeoutErrorCode, appErr.Code)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	_, err = client.CallWithContext(ctx, nil, "get", "/slow", "123", nil, nil)
+	assert.NotNil(t, err)
+	appErr, ok = err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	assert.Equal(t, clients.CancelledErrorCode, appErr.Code)
+}
@@ -0,0 +1,67 @@
+package test_clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadBalancingRestClient(t *testing.T) {
+	var calls1, calls2 int32
+	var failing int32
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls1, 1)
+		w.WriteHeader(204)
+	}))
+	defer server1.Close()
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls2, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer server2.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connections.server1.uri", server1.URL,
+		"connections.server2.uri", server2.URL,
+		"options.retries", 1,
+		"options.load_balancing", "round_robin",
+		"options.eject_failures", 2,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+	assert.Len(t, client.LoadBalancer.Endpoints(), 2)
+
+	for i := 0; i < 4; i++ {
+		_, err = client.Call(nil, "get", "/data", "123", nil, nil)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls1))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls2))
+
+	// Failed endpoint is ejected after repeated failures
+	atomic.StoreInt32(&failing, 1)
+	for i := 0; i < 4; i++ {
+		client.Call(nil, "get", "/data", "123", nil, nil)
+	}
+	assert.True(t, client.LoadBalancer.IsEjected(server2.URL))
+
+	atomic.StoreInt32(&calls2, 0)
+	for i := 0; i < 4; i++ {
+		_, err = client.Call(nil, "get", "/data", "123", nil, nil)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls2))
+}
@@ -0,0 +1,91 @@
+package test_clients
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyRestClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte("\"OK\""))
+	}))
+	defer server.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", server.URL,
+		"options.retries", 3,
+		"options.retry_initial_delay", 10,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	// Idempotent calls are retried on 503
+	res, err := client.Call(nil, "get", "/data", "123", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "\"OK\"", string(res.([]byte)))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// Non-idempotent calls are not retried
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.Call(nil, "post", "/data", "123", nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Unless they are explicitly marked as idempotent
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.CallWithContext(clients.WithIdempotent(context.Background()), nil, "post", "/data", "123", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := clients.ParseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	delay, ok = clients.ParseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.True(t, delay > 59*time.Minute)
+
+	_, ok = clients.ParseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestRetryPolicyNotSentCalls(t *testing.T) {
+	policy := clients.NewDefaultRetryPolicy()
+	req := httptest.NewRequest("POST", "http://localhost/commands", nil)
+
+	// Refused connections are retried for non-idempotent calls
+	dialErr := &url.Error{Op: "Post", URL: "http://localhost/commands",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	retry, _ := policy.ShouldRetry(req, nil, dialErr, 1, 0)
+	assert.True(t, retry)
+	assert.True(t, clients.IsNotSent(dialErr))
+
+	// Other transport errors are not
+	retry, _ = policy.ShouldRetry(req, nil, errors.New("connection reset"), 1, 0)
+	assert.False(t, retry)
+	assert.False(t, clients.IsNotSent(nil))
+}
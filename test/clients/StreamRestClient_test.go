@@ -0,0 +1,109 @@
+package test_clients
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamRestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(404)
+			w.Write([]byte(`{"code":"FILE_NOT_FOUND","status":404,"message":"File not found"}`))
+			return
+		}
+		assert.Equal(t, "123", r.URL.Query().Get("correlation_id"))
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		io.Copy(w, r.Body)
+	}))
+	defer server.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", server.URL,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	content := strings.Repeat("0123456789", 100000)
+	resp, err := client.CallStream(context.Background(), "post", "/files", "123", nil,
+		strings.NewReader(content), "application/octet-stream")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/octet-stream", resp.ContentType())
+	data, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Close())
+	assert.Equal(t, content, string(data))
+
+	_, err = client.CallStream(context.Background(), "get", "/missing", "123", nil, nil, "")
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	assert.Equal(t, 404, appErr.Status)
+	assert.Equal(t, "FILE_NOT_FOUND", appErr.Code)
+}
+
+func TestStreamRestClientRetriesFileBody(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(503)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", server.URL,
+		"options.retries", 3,
+		"options.retry_initial_delay", 10,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	content := strings.Repeat("0123456789", 10000)
+	file, err := ioutil.TempFile("", "stream_*.bin")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	_, err = file.WriteString(content)
+	assert.Nil(t, err)
+	_, err = file.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+
+	resp, err := client.CallStream(context.Background(), "put", "/files", "123", nil,
+		file, "application/octet-stream")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	data, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, resp.Close())
+	assert.Equal(t, content, string(data))
+
+	// The file is left open for the caller
+	_, err = file.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,81 @@
+package test_clients
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type traceSpanResult struct {
+	TraceId      string `json:"trace_id"`
+	ParentSpanId string `json:"parent_span_id"`
+	TraceState   string `json:"trace_state"`
+}
+
+type traceRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *traceRoutes) Register() {
+	c.endpoint.RegisterRoute("get", "/span", nil, func(res http.ResponseWriter, req *http.Request) {
+		span := services.SpanFromRequest(req)
+		services.HttpResponseSender.SendResult(res, req, traceSpanResult{
+			TraceId:      span.TraceId,
+			ParentSpanId: span.ParentSpanId,
+			TraceState:   span.TraceState,
+		}, nil)
+	})
+}
+
+func TestTraceRestClient(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+	endpoint.Register(&traceRoutes{endpoint: endpoint})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.uri", "http://"+endpoint.Addr(),
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err = client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	prototype := reflect.TypeOf(traceSpanResult{})
+
+	// Continues the trace of the incoming request
+	incoming, err := services.ParseTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "vendor=value")
+	assert.Nil(t, err)
+	ctx, timing := client.InstrumentWithContext(services.ContextWithSpan(context.Background(), incoming), "123", "dummy.span")
+	result, err := client.CallWithContext(ctx, prototype, "get", "/span", "123", nil, nil)
+	timing.EndTiming(err)
+	assert.Nil(t, err)
+
+	span := result.(*traceSpanResult)
+	assert.Equal(t, incoming.TraceId, span.TraceId)
+	assert.Equal(t, timing.Span().SpanId, span.ParentSpanId)
+	assert.Equal(t, incoming.SpanId, timing.Span().ParentSpanId)
+	assert.Equal(t, "vendor=value", span.TraceState)
+
+	// Starts a new trace without incoming span
+	result, err = client.Call(prototype, "get", "/span", "123", nil, nil)
+	assert.Nil(t, err)
+	span = result.(*traceSpanResult)
+	assert.Len(t, span.TraceId, 32)
+	assert.NotEqual(t, incoming.TraceId, span.TraceId)
+	assert.Len(t, span.ParentSpanId, 16)
+}
@@ -0,0 +1,43 @@
+package test_clients
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportRestClient(t *testing.T) {
+	client := clients.NewRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", DummyRestServicePort,
+		"options.connect_timeout", 500,
+		"options.max_idle_conns_per_host", 32,
+		"options.idle_conn_timeout", 5000,
+		"options.response_header_timeout", 2000,
+		"options.http2_enabled", false,
+		"options.proxy", "none",
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	assert.Equal(t, 500, client.ConnectTimeout)
+	transport, ok := client.Client.Transport.(*http.Transport)
+	assert.True(t, ok)
+	assert.Equal(t, 32, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 5*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 2*time.Second, transport.ResponseHeaderTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.Nil(t, transport.Proxy)
+
+	_, err = client.Call(nil, "get", "/dummies", "123", nil, nil)
+	assert.Nil(t, err)
+}
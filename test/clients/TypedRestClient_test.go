@@ -0,0 +1,76 @@
+package test_clients
+
+import (
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cdata "github.com/pip-services3-go/pip-services3-commons-go/data"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/clients"
+	tdata "github.com/pip-services3-go/pip-services3-rpc-go/test/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedRestClient(t *testing.T) {
+	client := NewDummyRestClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", DummyRestServicePort,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	_, err = clients.Call[clients.DataPage[tdata.Dummy]](&client.RestClient, "get", "/dummies", "123", nil, nil)
+	assert.Nil(t, err)
+
+	dummy, err := clients.Call[*tdata.Dummy](&client.RestClient, "get", "/dummies/unknown", "123", nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, dummy)
+
+	created, err := clients.Call[tdata.Dummy](&client.RestClient, "post", "/dummies", "123", nil,
+		tdata.Dummy{Key: "Typed", Content: "Typed content"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Typed", created.Key)
+	assert.NotEqual(t, "", created.Id)
+
+	dummy, err = clients.Call[*tdata.Dummy](&client.RestClient, "get", "/dummies/"+created.Id, "123", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "Typed content", dummy.Content)
+
+	_, err = clients.Call[*tdata.Dummy](&client.RestClient, "delete", "/dummies/"+created.Id, "123", nil, nil)
+	assert.Nil(t, err)
+}
+
+func TestTypedCommandableHttpClient(t *testing.T) {
+	client := NewDummyCommandableHttpClient()
+	client.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", DummyCommandableHttpServicePort,
+	))
+	client.SetReferences(cref.NewEmptyReferences())
+	err := client.Open("")
+	assert.Nil(t, err)
+	defer client.Close("")
+
+	_, err = clients.CallCommand[clients.DataPage[tdata.Dummy]](&client.CommandableHttpClient,
+		"get_dummies", "123", cdata.NewEmptyAnyValueMap())
+	assert.Nil(t, err)
+
+	values, err := clients.CallCommand[map[string]string](&client.CommandableHttpClient,
+		"check_correlation_id", "test_cor_id", cdata.NewEmptyAnyValueMap())
+	assert.Nil(t, err)
+	assert.Equal(t, "test_cor_id", values["correlationId"])
+
+	dummy, err := clients.CallCommand[*tdata.Dummy](&client.CommandableHttpClient,
+		"get_dummy_by_id", "123", cdata.NewAnyValueMapFromTuples("dummy_id", "unknown"))
+	assert.Nil(t, err)
+	assert.Nil(t, dummy)
+
+	total, err := clients.ConvertResult[int64]([]byte("42"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), total)
+}
@@ -12,6 +12,7 @@ func TestHttpConnectionResolver(t *testing.T) {
 
 	t.Run("HttpConnectionResolver.Resolve_URI", ResolveURI)
 	t.Run("HttpConnectionResolver.Resolve_Parameters", ResolveParameters)
+	t.Run("HttpConnectionResolver.Resolve_ZeroPort", ResolveZeroPort)
 }
 
 func ResolveURI(t *testing.T) {
@@ -40,3 +41,20 @@ func ResolveParameters(t *testing.T) {
 	assert.Equal(t, "http://somewhere.com:777", connection.Uri())
 
 }
+
+func ResolveZeroPort(t *testing.T) {
+	resolver := connect.NewHttpConnectionResolver()
+	resolver.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+
+	_, _, err := resolver.Resolve("")
+	assert.NotNil(t, err)
+
+	resolver.AllowZeroPort = true
+	connection, _, err := resolver.Resolve("")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, connection.Port())
+}
@@ -0,0 +1,156 @@
+package test_services
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	clog "github.com/pip-services3-go/pip-services3-components-go/log"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type accessLogCapture struct {
+	*clog.Logger
+	lock     sync.Mutex
+	messages []string
+}
+
+func newAccessLogCapture() *accessLogCapture {
+	c := &accessLogCapture{}
+	c.Logger = clog.InheritLogger(c)
+	return c
+}
+
+func (c *accessLogCapture) Write(level int, correlationId string, err error, message string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.messages = append(c.messages, message)
+}
+
+func (c *accessLogCapture) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.messages = nil
+}
+
+func (c *accessLogCapture) wait(count int) []string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.lock.Lock()
+		messages := append([]string{}, c.messages...)
+		c.lock.Unlock()
+		if len(messages) >= count || time.Now().After(deadline) {
+			return messages
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type accessLogRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *accessLogRoutes) Register() {
+	c.endpoint.RegisterRoute("get", "/items/{id}", nil, func(res http.ResponseWriter, req *http.Request) {
+		services.HttpResponseSender.SendResult(res, req, "item", nil)
+	})
+	c.endpoint.RegisterRoute("get", "/heartbeat", nil, func(res http.ResponseWriter, req *http.Request) {
+		services.HttpResponseSender.SendResult(res, req, "OK", nil)
+	})
+	c.endpoint.RegisterRouteWithAuth("get", "/me", nil, func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		next(res, req.WithContext(services.ContextWithUserId(req.Context(), "42")))
+	}, func(res http.ResponseWriter, req *http.Request) {
+		services.HttpResponseSender.SendResult(res, req, services.UserIdFromRequest(req), nil)
+	})
+}
+
+func openAccessLogEndpoint(t *testing.T, format string) (*services.HttpEndpoint, *accessLogCapture) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+		"options.access_log.enabled", true,
+		"options.access_log.format", format,
+	))
+	logger := newAccessLogCapture()
+	endpoint.SetReferences(crefer.NewReferencesFromTuples(
+		crefer.NewDescriptor("pip-services", "logger", "capture", "default", "1.0"), logger,
+	))
+	endpoint.Register(&accessLogRoutes{endpoint: endpoint})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	logger.clear()
+	return endpoint, logger
+}
+
+func TestHttpAccessLogJson(t *testing.T) {
+	endpoint, logger := openAccessLogEndpoint(t, "json")
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr()
+
+	resp, err := http.Get(url + "/heartbeat")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("GET", url+"/items/123?correlation_id=abc", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	messages := logger.wait(1)
+	assert.Len(t, messages, 1)
+
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(messages[0]), &entry))
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/items/{id}", entry["route"])
+	assert.Equal(t, "/items/123", entry["path"])
+	assert.Equal(t, float64(200), entry["status"])
+	assert.True(t, entry["bytes"].(float64) > 0)
+	assert.Equal(t, "127.0.0.1", entry["client_address"])
+	assert.Equal(t, "test-agent", entry["user_agent"])
+	assert.Equal(t, "abc", entry["correlation_id"])
+	assert.Len(t, entry["trace_id"], 32)
+	_, ok := entry["latency_ms"]
+	assert.True(t, ok)
+}
+
+func TestHttpAccessLogCombined(t *testing.T) {
+	endpoint, logger := openAccessLogEndpoint(t, "combined")
+	defer endpoint.Close("")
+
+	req, _ := http.NewRequest("GET", "http://"+endpoint.Addr()+"/missing", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("Referer", "http://example.com")
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	messages := logger.wait(1)
+	assert.Len(t, messages, 1)
+	line := messages[0]
+	assert.True(t, strings.HasPrefix(line, "127.0.0.1 - - ["))
+	assert.Contains(t, line, "\"GET /missing HTTP/1.1\" 404 ")
+	assert.True(t, strings.HasSuffix(line, "\"http://example.com\" \"test-agent\""))
+}
+
+func TestHttpAccessLogUser(t *testing.T) {
+	endpoint, logger := openAccessLogEndpoint(t, "common")
+	defer endpoint.Close("")
+
+	resp, err := http.Get("http://" + endpoint.Addr() + "/me")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	messages := logger.wait(1)
+	assert.Len(t, messages, 1)
+	assert.True(t, strings.HasPrefix(messages[0], "127.0.0.1 - 42 ["))
+}
@@ -0,0 +1,103 @@
+package test_services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cvalid "github.com/pip-services3-go/pip-services3-commons-go/validate"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type bodyLimitRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *bodyLimitRoutes) echo(res http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		services.HttpResponseSender.SendError(res, req, err)
+		return
+	}
+	services.HttpResponseSender.SendResult(res, req, len(body), nil)
+}
+
+func (c *bodyLimitRoutes) Register() {
+	c.endpoint.RegisterRoute("post", "/echo", nil, c.echo)
+	c.endpoint.RegisterRoute("post", "/validated", &cvalid.NewObjectSchema().Schema, c.echo)
+	c.endpoint.RegisterRouteWithOptions("post", "/large", nil,
+		&services.RouteOptions{RequestMaxSize: 4096}, c.echo)
+	c.endpoint.RegisterRouteWithOptions("post", "/unlimited", nil,
+		&services.RouteOptions{RequestMaxSize: -1}, c.echo)
+}
+
+func TestHttpBodyLimit(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", HttpBodyLimitEndpointPort,
+		"options.request_max_size", 1024,
+		"options.file_max_size", 2048,
+	))
+	endpoint.Register(&bodyLimitRoutes{endpoint: endpoint})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+
+	url := fmt.Sprintf("http://localhost:%d", HttpBodyLimitEndpointPort)
+	post := func(route string, contentType string, size int, chunked bool) (int, string) {
+		var body io.Reader = strings.NewReader(strings.Repeat("x", size))
+		if chunked {
+			// Hide content length to force streaming check
+			body = ioutil.NopCloser(body)
+		}
+		resp, err := http.Post(url+route, contentType, body)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	status, body := post("/echo", "text/plain", 1024, false)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "1024", body)
+
+	for _, chunked := range []bool{false, true} {
+		for _, route := range []string{"/echo", "/validated"} {
+			status, body = post(route, "text/plain", 1025, chunked)
+			assert.Equal(t, 413, status, route)
+			var errDesc cerr.ErrorDescription
+			assert.Nil(t, json.Unmarshal([]byte(body), &errDesc))
+			assert.Equal(t, services.RequestTooLargeErrorCode, errDesc.Code)
+			assert.Equal(t, 413, errDesc.Status)
+		}
+	}
+
+	status, _ = post("/echo", "multipart/form-data; boundary=xyz", 2048, true)
+	assert.Equal(t, 200, status)
+	status, _ = post("/echo", "multipart/form-data; boundary=xyz", 2049, false)
+	assert.Equal(t, 413, status)
+
+	status, _ = post("/large", "text/plain", 4096, true)
+	assert.Equal(t, 200, status)
+	status, _ = post("/large", "text/plain", 4097, true)
+	assert.Equal(t, 413, status)
+
+	status, body = post("/unlimited", "text/plain", 100000, false)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "100000", body)
+
+	resp, err := http.Post(url+"/echo", "text/plain", bytes.NewReader(nil))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+}
@@ -0,0 +1,56 @@
+package test_services
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	tdata "github.com/pip-services3-go/pip-services3-rpc-go/test/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpCodecsNegotiate(t *testing.T) {
+	codecs := services.HttpCodecs
+
+	assert.Equal(t, "application/json", codecs.Negotiate("").ContentType())
+	assert.Equal(t, "application/json", codecs.Negotiate("*/*").ContentType())
+	assert.Equal(t, "application/msgpack", codecs.Negotiate("application/x-msgpack").ContentType())
+	assert.Equal(t, "application/xml", codecs.Negotiate("application/xml, */*;q=0.1").ContentType())
+	assert.Equal(t, "application/xml", codecs.Negotiate("text/html, application/xml;q=0.9").ContentType())
+	// Browsers that accept any media type get the default codec
+	assert.Equal(t, "application/json", codecs.Negotiate("text/html, application/xml;q=0.9, */*;q=0.1").ContentType())
+	assert.Equal(t, "application/json", codecs.Negotiate(
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8").ContentType())
+	assert.Equal(t, "application/json", codecs.Negotiate("application/xml;q=0.5, application/json").ContentType())
+	assert.Equal(t, "application/json", codecs.Negotiate("text/html").ContentType())
+	assert.Equal(t, "application/x-www-form-urlencoded",
+		codecs.ForContentType("application/x-www-form-urlencoded; charset=utf-8").ContentType())
+}
+
+func TestHttpCodecsRoundTrip(t *testing.T) {
+	dummy := tdata.Dummy{Id: "1", Key: "Key 1", Content: "Content 1"}
+
+	for _, contentType := range []string{"application/json", "application/xml",
+		"application/x-www-form-urlencoded", "application/msgpack"} {
+
+		codec, ok := services.HttpCodecs.Get(contentType)
+		assert.True(t, ok)
+
+		data, err := codec.Encode(dummy)
+		assert.Nil(t, err, contentType)
+
+		var result tdata.Dummy
+		err = codec.Decode(data, &result)
+		assert.Nil(t, err, contentType)
+		assert.Equal(t, dummy, result, contentType)
+	}
+
+	form := services.NewFormCodec()
+	var values url.Values
+	err := form.Decode([]byte("key=a&tags=x&tags=y"), &values)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "y"}, values["tags"])
+
+	_, err = services.NewProtobufCodec().Encode(dummy)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,102 @@
+package test_services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	assert.Equal(t, "", services.NegotiateEncoding(""))
+	assert.Equal(t, "", services.NegotiateEncoding("identity"))
+	assert.Equal(t, "br", services.NegotiateEncoding("gzip, deflate, br"))
+	assert.Equal(t, "gzip", services.NegotiateEncoding("br;q=0.5, gzip"))
+	assert.Equal(t, "deflate", services.NegotiateEncoding("br;q=0, deflate"))
+	assert.Equal(t, "gzip", services.NegotiateEncoding("*"))
+}
+
+func TestHttpCompression(t *testing.T) {
+	compression := services.NewHttpCompression()
+	compression.Configure(cconf.NewConfigParamsFromTuples(
+		"options.compression.enabled", true,
+		"options.compression.min_size", 100,
+		"options.compression.content_types", "application/json",
+	))
+
+	large := `{"data":"` + strings.Repeat("x", 1000) + `"}`
+	handler := compression.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/echo":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		case "/small":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte(large))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(large))
+		}
+	}))
+
+	send := func(path string, acceptEncoding string, body []byte, contentEncoding string) *http.Response {
+		req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	// Gzip response
+	resp := send("/large", "gzip", nil, "")
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"))
+	reader, err := gzip.NewReader(resp.Body)
+	assert.Nil(t, err)
+	data, _ := ioutil.ReadAll(reader)
+	assert.Equal(t, large, string(data))
+
+	// Brotli response
+	resp = send("/large", "gzip, br", nil, "")
+	assert.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+	data, _ = ioutil.ReadAll(brotli.NewReader(resp.Body))
+	assert.Equal(t, large, string(data))
+
+	// Small, not allowed and not accepted responses are not compressed
+	for _, path := range []string{"/small", "/binary"} {
+		resp = send(path, "gzip", nil, "")
+		assert.Equal(t, "", resp.Header.Get("Content-Encoding"), path)
+	}
+	resp = send("/large", "", nil, "")
+	assert.Equal(t, "", resp.Header.Get("Content-Encoding"))
+	data, _ = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, large, string(data))
+
+	// Compressed request body
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	writer.Write([]byte(large))
+	writer.Close()
+	resp = send("/echo", "", buffer.Bytes(), "gzip")
+	assert.Equal(t, 200, resp.StatusCode)
+	data, _ = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, large, string(data))
+
+	resp = send("/echo", "", []byte("not compressed"), "gzip")
+	assert.Equal(t, 400, resp.StatusCode)
+}
@@ -0,0 +1,163 @@
+package test_services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type concurrencyRoutes struct {
+	endpoint *services.HttpEndpoint
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (c *concurrencyRoutes) Register() {
+	block := func(res http.ResponseWriter, req *http.Request) {
+		c.started <- struct{}{}
+		<-c.release
+		services.HttpResponseSender.SendResult(res, req, "ok", nil)
+	}
+	c.endpoint.RegisterRoute("get", "/block", nil, block)
+	c.endpoint.RegisterRouteWithOptions("get", "/single", nil,
+		&services.RouteOptions{MaxInFlight: 1}, block)
+}
+
+func openConcurrencyEndpoint(t *testing.T, config *cconf.ConfigParams) (*services.HttpEndpoint, *concurrencyRoutes) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	).Override(config))
+	routes := &concurrencyRoutes{
+		endpoint: endpoint,
+		started:  make(chan struct{}, 10),
+		release:  make(chan struct{}),
+	}
+	endpoint.Register(routes)
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	return endpoint, routes
+}
+
+func getAsync(url string) chan *http.Response {
+	result := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			result <- nil
+			return
+		}
+		resp.Body.Close()
+		result <- resp
+	}()
+	return result
+}
+
+func getOverloadReason(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	var errDesc cerr.ErrorDescription
+	json.Unmarshal(body, &errDesc)
+	if errDesc.Code != services.ServerOverloadedErrorCode {
+		return resp.StatusCode, ""
+	}
+	return resp.StatusCode, errDesc.Details["reason"].(string)
+}
+
+func TestHttpEndpointConcurrencyLimit(t *testing.T) {
+	endpoint, routes := openConcurrencyEndpoint(t, cconf.NewConfigParamsFromTuples(
+		"options.concurrency.max_in_flight", 1,
+		"options.concurrency.queue_size", 1,
+		"options.concurrency.queue_timeout", 300,
+	))
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr() + "/block"
+
+	first := getAsync(url)
+	<-routes.started
+	second := getAsync(url)
+	time.Sleep(100 * time.Millisecond)
+
+	// Queue is full
+	status, reason := getOverloadReason(t, url)
+	assert.Equal(t, 503, status)
+	assert.Equal(t, "queue_full", reason)
+
+	// Queued request is processed when the slot is released
+	routes.release <- struct{}{}
+	<-routes.started
+	routes.release <- struct{}{}
+	assert.Equal(t, 200, (<-first).StatusCode)
+	assert.Equal(t, 200, (<-second).StatusCode)
+
+	// Queue timeout
+	first = getAsync(url)
+	<-routes.started
+	status, reason = getOverloadReason(t, url)
+	assert.Equal(t, 503, status)
+	assert.Equal(t, "queue_timeout", reason)
+	routes.release <- struct{}{}
+	assert.Equal(t, 200, (<-first).StatusCode)
+}
+
+func TestHttpEndpointRouteConcurrencyLimit(t *testing.T) {
+	endpoint, routes := openConcurrencyEndpoint(t, cconf.NewEmptyConfigParams())
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr()
+
+	first := getAsync(url + "/single")
+	<-routes.started
+
+	status, reason := getOverloadReason(t, url+"/single")
+	assert.Equal(t, 503, status)
+	assert.Equal(t, "max_in_flight", reason)
+
+	// Other routes are not limited
+	second := getAsync(url + "/block")
+	<-routes.started
+
+	routes.release <- struct{}{}
+	routes.release <- struct{}{}
+	assert.Equal(t, 200, (<-first).StatusCode)
+	assert.Equal(t, 200, (<-second).StatusCode)
+}
+
+func TestHttpConcurrencyLimiterConfigureWhileServing(t *testing.T) {
+	limiter := services.NewHttpConcurrencyLimiter(nil)
+	limiter.Configure(cconf.NewConfigParamsFromTuples(
+		"options.concurrency.max_in_flight", 1,
+		"options.concurrency.queue_size", 10,
+		"options.concurrency.queue_timeout", 100,
+	))
+	handler := limiter.Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}))
+
+	done := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		go func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+			done <- struct{}{}
+		}()
+	}
+	// Queue settings can be changed while requests wait in the queue
+	for i := 0; i < 5; i++ {
+		<-done
+		limiter.Configure(cconf.NewConfigParamsFromTuples(
+			"options.concurrency.adaptive_shedding", i%2 == 0,
+			"options.concurrency.target_delay", 10*i,
+		))
+	}
+}
@@ -0,0 +1,140 @@
+package test_services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	ccon "github.com/pip-services3-go/pip-services3-components-go/connect"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type drainDiscovery struct {
+	connection   *ccon.ConnectionParams
+	registered   []string
+	unregistered []string
+}
+
+func (c *drainDiscovery) Register(correlationId string, key string,
+	connection *ccon.ConnectionParams) (*ccon.ConnectionParams, error) {
+	c.registered = append(c.registered, key)
+	return connection, nil
+}
+
+func (c *drainDiscovery) ResolveOne(correlationId string, key string) (*ccon.ConnectionParams, error) {
+	return c.connection, nil
+}
+
+func (c *drainDiscovery) ResolveAll(correlationId string, key string) ([]*ccon.ConnectionParams, error) {
+	return []*ccon.ConnectionParams{c.connection}, nil
+}
+
+func (c *drainDiscovery) Unregister(correlationId string, key string, connection *ccon.ConnectionParams) error {
+	c.unregistered = append(c.unregistered, key)
+	return nil
+}
+
+type drainRoutes struct {
+	endpoint *services.HttpEndpoint
+	release  chan struct{}
+}
+
+func (c *drainRoutes) Register() {
+	c.endpoint.RegisterRoute("get", "/slow", nil, func(res http.ResponseWriter, req *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		services.HttpResponseSender.SendResult(res, req, "done", nil)
+	})
+	c.endpoint.RegisterRoute("get", "/hang", nil, func(res http.ResponseWriter, req *http.Request) {
+		<-c.release
+	})
+}
+
+func openDrainEndpoint(t *testing.T, drainTimeout int) (*services.HttpEndpoint, *drainDiscovery, *drainRoutes) {
+	discovery := &drainDiscovery{
+		connection: ccon.NewConnectionParamsFromTuples(
+			"discovery_key", "drain",
+			"protocol", "http",
+			"host", "localhost",
+			"port", HttpDrainEndpointPort,
+		),
+	}
+
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.discovery_key", "drain",
+		"options.drain_timeout", drainTimeout,
+		"options.pre_stop_delay", 100,
+	))
+	endpoint.SetReferences(cref.NewReferencesFromTuples(
+		cref.NewDescriptor("pip-services", "discovery", "test", "default", "1.0"), discovery,
+	))
+	routes := &drainRoutes{endpoint: endpoint, release: make(chan struct{})}
+	endpoint.Register(routes)
+
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	assert.True(t, endpoint.IsReady())
+	assert.Equal(t, []string{"drain"}, discovery.registered)
+	return endpoint, discovery, routes
+}
+
+func TestHttpEndpointDrain(t *testing.T) {
+	endpoint, discovery, _ := openDrainEndpoint(t, 2000)
+	url := fmt.Sprintf("http://localhost:%d/slow", HttpDrainEndpointPort)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, err := http.Get(url)
+		assert.Nil(t, err)
+		if err == nil {
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, 200, resp.StatusCode)
+			assert.Equal(t, `"done"`, string(body))
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, endpoint.ActiveRequests())
+
+	err := endpoint.Close("")
+	assert.Nil(t, err)
+	assert.False(t, endpoint.IsReady())
+	assert.Equal(t, []string{"drain"}, discovery.unregistered)
+	wg.Wait()
+	assert.Equal(t, 0, endpoint.ActiveRequests())
+}
+
+func TestHttpEndpointForceClose(t *testing.T) {
+	endpoint, _, routes := openDrainEndpoint(t, 200)
+	defer close(routes.release)
+	url := fmt.Sprintf("http://localhost:%d/hang", HttpDrainEndpointPort)
+
+	done := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, endpoint.ActiveRequests())
+
+	start := time.Now()
+	err := endpoint.Close("")
+	assert.Nil(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// The request is cut off
+	assert.NotNil(t, <-done)
+}
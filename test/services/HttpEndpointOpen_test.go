@@ -0,0 +1,89 @@
+package test_services
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpEndpointRandomPort(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+	assert.Equal(t, "", endpoint.Addr())
+
+	service := services.NewHeartbeatRestService()
+	service.SetReferences(cref.NewReferencesFromTuples(
+		cref.NewDescriptor("pip-services", "endpoint", "http", "default", "1.0"), endpoint,
+	))
+
+	start := time.Now()
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+
+	_, portStr, err := net.SplitHostPort(endpoint.Addr())
+	assert.Nil(t, err)
+	port, _ := strconv.Atoi(portStr)
+	assert.NotEqual(t, 0, port)
+
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/heartbeat", port))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestHttpEndpointRandomPortReopen(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	addr := endpoint.Addr()
+	err = endpoint.Close("")
+	assert.Nil(t, err)
+
+	// Occupy the previously assigned port, reopening must bind a new one
+	listener, err := net.Listen("tcp", addr)
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	err = endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	assert.NotEqual(t, addr, endpoint.Addr())
+}
+
+func TestHttpEndpointPortConflict(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", HttpEndpointServicePort,
+	))
+
+	err := endpoint.Open("")
+	assert.NotNil(t, err)
+	appErr, ok := err.(*cerr.ApplicationError)
+	assert.True(t, ok)
+	assert.Equal(t, "CANNOT_BIND", appErr.Code)
+	assert.False(t, endpoint.IsOpen())
+	assert.Equal(t, "", endpoint.Addr())
+}
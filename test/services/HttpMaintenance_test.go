@@ -0,0 +1,167 @@
+package test_services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	cref "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type maintenanceRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *maintenanceRoutes) Register() {
+	c.endpoint.RegisterRoute("get", "/data", nil, func(res http.ResponseWriter, req *http.Request) {
+		services.HttpResponseSender.SendResult(res, req, "data", nil)
+	})
+	c.endpoint.RegisterRoute("get", "/v1/orders/status", nil, func(res http.ResponseWriter, req *http.Request) {
+		services.HttpResponseSender.SendResult(res, req, "orders", nil)
+	})
+}
+
+func authorizeAdmin(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+	if req.Header.Get("Authorization") != "Bearer admin" {
+		services.HttpResponseSender.SendError(res, req,
+			cerr.NewUnauthorizedError("", "NOT_SIGNED", "User must be signed in").WithStatus(401))
+		return
+	}
+	next.ServeHTTP(res, req)
+}
+
+func TestHttpEndpointMaintenance(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+		"options.maintenance_enabled", true,
+		"options.maintenance_retry_after", 120,
+		"options.maintenance_route", "admin/maintenance",
+	))
+	endpoint.Register(&maintenanceRoutes{endpoint: endpoint})
+	endpoint.SetMaintenanceAuthorizer(authorizeAdmin)
+	heartbeat := services.NewHeartbeatRestService()
+	heartbeat.SetReferences(cref.NewReferencesFromTuples(
+		cref.NewDescriptor("pip-services", "endpoint", "http", "default", "1.0"), endpoint,
+	))
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+
+	url := "http://" + endpoint.Addr()
+	get := func(route string) (*http.Response, string) {
+		resp, err := http.Get(url + route)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := get("/data")
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, "120", resp.Header.Get("Retry-After"))
+	var errDesc cerr.ErrorDescription
+	assert.Nil(t, json.Unmarshal([]byte(body), &errDesc))
+	assert.Equal(t, services.MaintenanceErrorCode, errDesc.Code)
+
+	resp, _ = get("/heartbeat")
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Routes that only end with allowed routes are not available
+	resp, _ = get("/v1/orders/status")
+	assert.Equal(t, 503, resp.StatusCode)
+
+	post := func(route string, body string, token string) *http.Response {
+		req, err := http.NewRequest("POST", url+route, strings.NewReader(body))
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// Anonymous callers cannot change maintenance mode
+	resp = post("/admin/maintenance", `{"enabled":false}`, "")
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.True(t, endpoint.IsMaintenance())
+
+	// Turn off maintenance with admin route
+	resp = post("/admin/maintenance", `{"enabled":false}`, "admin")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.False(t, endpoint.IsMaintenance())
+
+	resp, body = get("/data")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, `"data"`, body)
+
+	endpoint.SetMaintenance(true, "Database upgrade")
+	req, _ := http.NewRequest("GET", url+"/admin/maintenance", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	bodyBytes, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	body = string(bodyBytes)
+	assert.Equal(t, 200, resp.StatusCode)
+	var state services.MaintenanceState
+	assert.Nil(t, json.Unmarshal([]byte(body), &state))
+	assert.Equal(t, services.MaintenanceState{Enabled: true, Reason: "Database upgrade"}, state)
+
+	resp, body = get("/data")
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Contains(t, body, "Database upgrade")
+}
+
+func TestHttpEndpointMaintenanceRouteWithoutAuthorizer(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+		"options.maintenance_route", "admin/maintenance",
+	))
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+
+	resp, err := http.Post("http://"+endpoint.Addr()+"/admin/maintenance", "application/json",
+		strings.NewReader(`{"enabled":true}`))
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 404, resp.StatusCode)
+	assert.False(t, endpoint.IsMaintenance())
+}
+
+func TestHttpRequestDetectorAddress(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	assert.Equal(t, "10.0.0.1", services.HttpRequestDetector.DetectAddress(req))
+
+	// Forwarding headers are not trusted by default
+	req.Header.Set("X-Real-IP", "10.0.0.2")
+	assert.Equal(t, "10.0.0.1", services.HttpRequestDetector.DetectAddress(req))
+	assert.Equal(t, "10.0.0.1", services.HttpRequestDetector.DetectForwardedAddress(req, nil))
+
+	proxies, err := services.ParseTrustedProxies("10.0.0.0/24, 172.16.0.5")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2", services.HttpRequestDetector.DetectForwardedAddress(req, proxies))
+
+	req.Header.Set("X-Forwarded-For", "192.168.1.1, 192.168.1.2, 172.16.0.5")
+	assert.Equal(t, "192.168.1.2", services.HttpRequestDetector.DetectForwardedAddress(req, proxies))
+
+	_, err = services.ParseTrustedProxies("10.0.0.0/33, proxy")
+	assert.NotNil(t, err)
+}
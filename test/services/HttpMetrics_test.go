@@ -0,0 +1,93 @@
+package test_services
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type metricsCountersCapture struct {
+	*ccount.CachedCounters
+}
+
+func (c *metricsCountersCapture) Save(counters []*ccount.Counter) error {
+	return nil
+}
+
+type metricsRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *metricsRoutes) Register() {
+	c.endpoint.RegisterRoute("post", "/items/{id}", nil, func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("fail") != "" {
+			res.WriteHeader(500)
+			return
+		}
+		services.HttpResponseSender.SendResult(res, req, "item", nil)
+	})
+}
+
+func TestHttpEndpointMetrics(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+	counters := &metricsCountersCapture{}
+	counters.CachedCounters = ccount.InheritCacheCounters(counters)
+	endpoint.SetReferences(crefer.NewReferencesFromTuples(
+		crefer.NewDescriptor("pip-services", "counters", "capture", "default", "1.0"), counters,
+	))
+	endpoint.Register(&metricsRoutes{endpoint: endpoint})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr()
+
+	for _, path := range []string{"/items/1", "/items/2", "/items/3?fail=true"} {
+		resp, err := http.Post(url+path, "application/json", bytes.NewBufferString("{}"))
+		assert.Nil(t, err)
+		resp.Body.Close()
+	}
+	resp, err := http.Get(url + "/missing/1")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	// Records are completed after responses are sent
+	time.Sleep(50 * time.Millisecond)
+
+	metrics := endpoint.Metrics()
+	assert.Len(t, metrics, 2)
+
+	route := metrics[0]
+	assert.Equal(t, "POST", route.Method)
+	assert.Equal(t, "/items/{id}", route.Route)
+	assert.Equal(t, int64(3), route.Count)
+	assert.Equal(t, int64(2), route.StatusClasses["2xx"])
+	assert.Equal(t, int64(1), route.StatusClasses["5xx"])
+	assert.Equal(t, int64(0), route.InFlight)
+	assert.Equal(t, int64(6), route.RequestSize)
+	assert.True(t, route.ResponseSize > 0)
+	assert.Equal(t, int64(3), route.DurationBuckets[len(route.DurationBuckets)-1])
+
+	unmatched := metrics[1]
+	assert.Equal(t, "GET", unmatched.Method)
+	assert.Equal(t, services.UnmatchedRoute, unmatched.Route)
+	assert.Equal(t, int64(1), unmatched.StatusClasses["4xx"])
+
+	counter := counters.Get("http_endpoint.POST /items/{id}.count", ccount.Increment)
+	assert.Equal(t, 3, counter.Count)
+	counter = counters.Get("http_endpoint.POST /items/{id}.5xx", ccount.Increment)
+	assert.Equal(t, 1, counter.Count)
+	counter = counters.Get("http_endpoint.POST /items/{id}.time", ccount.Interval)
+	assert.Equal(t, 3, counter.Count)
+}
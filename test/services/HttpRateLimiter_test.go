@@ -0,0 +1,173 @@
+package test_services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := services.NewMemoryRateLimitStore()
+
+	for _, algorithm := range []string{services.RateLimitTokenBucket, services.RateLimitSlidingWindow} {
+		for i := 0; i < 3; i++ {
+			result, err := store.Take("", algorithm+":a", algorithm, 3, time.Hour)
+			assert.Nil(t, err)
+			assert.True(t, result.Allowed, algorithm)
+			assert.Equal(t, 2-i, result.Remaining, algorithm)
+		}
+		result, err := store.Take("", algorithm+":a", algorithm, 3, time.Hour)
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed, algorithm)
+		assert.True(t, result.RetryAfter > 0, algorithm)
+
+		// Other keys are not affected
+		result, err = store.Take("", algorithm+":b", algorithm, 3, time.Hour)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed, algorithm)
+	}
+
+	// Tokens are refilled within the window
+	for i := 0; i < 2; i++ {
+		result, _ := store.Take("", "refill", services.RateLimitTokenBucket, 2, 200*time.Millisecond)
+		assert.True(t, result.Allowed)
+	}
+	result, _ := store.Take("", "refill", services.RateLimitTokenBucket, 2, 200*time.Millisecond)
+	assert.False(t, result.Allowed)
+	time.Sleep(150 * time.Millisecond)
+	result, _ = store.Take("", "refill", services.RateLimitTokenBucket, 2, 200*time.Millisecond)
+	assert.True(t, result.Allowed)
+}
+
+type rateLimitRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *rateLimitRoutes) Register() {
+	action := func(res http.ResponseWriter, req *http.Request) {
+		services.HttpResponseSender.SendResult(res, req, "ok", nil)
+	}
+	c.endpoint.RegisterRoute("get", "/open", nil, action)
+	c.endpoint.RegisterRouteWithOptions("get", "/limited", nil,
+		&services.RouteOptions{RateLimit: 2}, action)
+}
+
+func TestHttpEndpointRateLimit(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+		"options.rate_limit.enabled", true,
+		"options.rate_limit.limit", 5,
+		"options.rate_limit.key", "header:X-Client-Id",
+	))
+	endpoint.Register(&rateLimitRoutes{endpoint: endpoint})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+
+	get := func(route string, client string) *http.Response {
+		req, _ := http.NewRequest("GET", "http://"+endpoint.Addr()+route, nil)
+		req.Header.Set("X-Client-Id", client)
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// Route limit
+	assert.Equal(t, 200, get("/limited", "client1").StatusCode)
+	resp := get("/limited", "client1")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	resp = get("/limited", "client1")
+	assert.Equal(t, 429, resp.StatusCode)
+	assert.NotEqual(t, "", resp.Header.Get("Retry-After"))
+
+	// Endpoint limit counts all requests of the client
+	assert.Equal(t, 200, get("/open", "client1").StatusCode)
+	assert.Equal(t, 200, get("/open", "client1").StatusCode)
+	assert.Equal(t, 429, get("/open", "client1").StatusCode)
+
+	// Other clients are not limited
+	resp = get("/open", "client2")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "5", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "4", resp.Header.Get("X-RateLimit-Remaining"))
+}
+
+func TestHttpRateLimiterTrustedProxies(t *testing.T) {
+	limiter := services.NewHttpRateLimiter(nil, nil)
+	limiter.Configure(cconf.NewConfigParamsFromTuples(
+		"options.rate_limit.limit", 1,
+		"options.rate_limit.trusted_proxies", "10.0.0.0/8",
+	))
+	interceptor := limiter.Interceptor()
+	call := func(remoteAddr string, forwardedFor string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		res := httptest.NewRecorder()
+		interceptor(res, req, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+		})
+		return res.Code
+	}
+
+	// Forwarding headers from untrusted clients are ignored
+	assert.Equal(t, 200, call("192.168.1.1:5000", "1.1.1.1"))
+	assert.Equal(t, 429, call("192.168.1.1:5000", "2.2.2.2"))
+
+	// Spoofed left-most addresses do not change the client behind trusted proxies
+	assert.Equal(t, 200, call("10.0.0.1:5000", "1.1.1.1, 8.8.8.8, 10.0.0.2"))
+	assert.Equal(t, 429, call("10.0.0.1:5000", "2.2.2.2, 8.8.8.8, 10.0.0.2"))
+}
+
+type userRateLimitRoutes struct {
+	endpoint *services.HttpEndpoint
+}
+
+func (c *userRateLimitRoutes) Register() {
+	authorize := func(res http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		next(res, req.WithContext(services.ContextWithUserId(req.Context(), req.Header.Get("X-User"))))
+	}
+	c.endpoint.RegisterRouteWithAuthAndOptions("get", "/user", nil, &services.RouteOptions{RateLimit: 1}, authorize,
+		func(res http.ResponseWriter, req *http.Request) {
+			services.HttpResponseSender.SendResult(res, req, "ok", nil)
+		})
+}
+
+func TestHttpEndpointUserRateLimit(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+		"options.rate_limit.key", "user",
+	))
+	endpoint.Register(&userRateLimitRoutes{endpoint: endpoint})
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+
+	get := func(user string) int {
+		req, _ := http.NewRequest("GET", "http://"+endpoint.Addr()+"/user", nil)
+		req.Header.Set("X-User", user)
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Route limit is applied per authenticated user from the same address
+	assert.Equal(t, 200, get("user1"))
+	assert.Equal(t, 429, get("user1"))
+	assert.Equal(t, 200, get("user2"))
+}
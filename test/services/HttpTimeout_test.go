@@ -0,0 +1,90 @@
+package test_services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	cerr "github.com/pip-services3-go/pip-services3-commons-go/errors"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutRoutes struct {
+	endpoint  *services.HttpEndpoint
+	cancelled chan bool
+}
+
+func (c *timeoutRoutes) Register() {
+	c.endpoint.RegisterRoute("get", "/fast", nil, func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("X-Result", "fast")
+		services.HttpResponseSender.SendCreatedResult(res, req, "ok", nil)
+	})
+	c.endpoint.RegisterRoute("get", "/slow", nil, func(res http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+			c.cancelled <- true
+		case <-time.After(time.Second):
+			c.cancelled <- false
+		}
+		services.HttpResponseSender.SendResult(res, req, "late", nil)
+	})
+	c.endpoint.RegisterRouteWithOptions("get", "/unlimited", nil,
+		&services.RouteOptions{Timeout: -1}, func(res http.ResponseWriter, req *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+			services.HttpResponseSender.SendResult(res, req, "ok", nil)
+		})
+}
+
+func TestHttpEndpointTimeouts(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+		"options.request_timeout", 100,
+		"options.read_header_timeout", 200,
+	))
+	routes := &timeoutRoutes{endpoint: endpoint, cancelled: make(chan bool, 1)}
+	endpoint.Register(routes)
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr()
+
+	resp, err := http.Get(url + "/fast")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "fast", resp.Header.Get("X-Result"))
+
+	resp, err = http.Get(url + "/slow")
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, 504, resp.StatusCode)
+	var errDesc cerr.ErrorDescription
+	assert.Nil(t, json.Unmarshal(body, &errDesc))
+	assert.Equal(t, services.TimeoutErrorCode, errDesc.Code)
+	assert.True(t, <-routes.cancelled)
+
+	resp, err = http.Get(url + "/unlimited")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Connections with incomplete headers are closed
+	conn, err := net.Dial("tcp", endpoint.Addr())
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /fast HTTP/1.1\r\nHost: localhost\r\n"))
+	assert.Nil(t, err)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	start := time.Now()
+	ioutil.ReadAll(conn)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
@@ -23,6 +23,8 @@ const (
 	DummyOpenAPIFileRestServicePort
 	DummyCommandableHttpServicePort
 	DummyCommandableSwaggerHttpServicePort
+	HttpBodyLimitEndpointPort
+	HttpDrainEndpointPort
 )
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,71 @@
+package test_services
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	crefer "github.com/pip-services3-go/pip-services3-commons-go/refer"
+	ccount "github.com/pip-services3-go/pip-services3-components-go/count"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsRestService(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+	counters := &metricsCountersCapture{}
+	counters.CachedCounters = ccount.InheritCacheCounters(counters)
+	service := services.NewMetricsRestService()
+	service.Configure(cconf.NewConfigParamsFromTuples(
+		"route", "prometheus",
+		"prefix", "test_",
+	))
+
+	references := crefer.NewReferencesFromTuples(
+		crefer.NewDescriptor("pip-services", "counters", "capture", "default", "1.0"), counters,
+		crefer.NewDescriptor("pip-services", "endpoint", "http", "default", "1.0"), endpoint,
+		crefer.NewDescriptor("pip-services", "metrics-service", "http", "default", "1.0"), service,
+	)
+	endpoint.SetReferences(references)
+	service.SetReferences(references)
+
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	err = service.Open("")
+	assert.Nil(t, err)
+	defer service.Close("")
+
+	counters.IncrementOne("mycomponent.exec_count")
+	counters.Last("mycomponent.queue-size", 5)
+
+	url := "http://" + endpoint.Addr() + "/prometheus"
+	resp, err := http.Get(url)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err = http.Get(url)
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, services.PrometheusContentType, resp.Header.Get("Content-Type"))
+
+	text := string(body)
+	assert.Contains(t, text, "# TYPE test_http_requests_total counter\n")
+	assert.Contains(t, text, "test_http_requests_total{method=\"GET\",route=\"/prometheus\",status_class=\"2xx\"} 1\n")
+	assert.Contains(t, text, "test_http_requests_in_flight{method=\"GET\",route=\"/prometheus\"} 1\n")
+	assert.Contains(t, text, "test_http_request_duration_seconds_bucket{method=\"GET\",route=\"/prometheus\",le=\"+Inf\"} 1\n")
+	assert.Contains(t, text, "test_http_request_duration_seconds_count{method=\"GET\",route=\"/prometheus\"} 1\n")
+	assert.Contains(t, text, "# TYPE test_mycomponent_exec_count counter\ntest_mycomponent_exec_count 1\n")
+	assert.Contains(t, text, "# TYPE test_mycomponent_queue_size gauge\ntest_mycomponent_queue_size 5\n")
+	assert.NotContains(t, text, "http_endpoint_GET")
+}
@@ -0,0 +1,120 @@
+package test_services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cconf "github.com/pip-services3-go/pip-services3-commons-go/config"
+	clog "github.com/pip-services3-go/pip-services3-components-go/log"
+	"github.com/pip-services3-go/pip-services3-rpc-go/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	span, err := services.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "congo=t61rcWkgMzE")
+	assert.Nil(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceId)
+	assert.Equal(t, "00f067aa0ba902b7", span.SpanId)
+	assert.True(t, span.IsSampled())
+	assert.Equal(t, "congo=t61rcWkgMzE", span.TraceState)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", span.TraceParent())
+
+	child := span.NewChild()
+	assert.Equal(t, span.TraceId, child.TraceId)
+	assert.Equal(t, span.SpanId, child.ParentSpanId)
+	assert.NotEqual(t, span.SpanId, child.SpanId)
+	assert.Equal(t, span.TraceState, child.TraceState)
+
+	// Future versions may add fields
+	_, err = services.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra", "")
+	assert.Nil(t, err)
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+	} {
+		_, err = services.ParseTraceParent(value, "")
+		assert.NotNil(t, err, value)
+	}
+
+	ctx, root := services.StartSpan(context.Background())
+	assert.Equal(t, root, services.SpanFromContext(ctx))
+	assert.Equal(t, "", root.ParentSpanId)
+	_, child = services.StartSpan(ctx)
+	assert.Equal(t, root.TraceId, child.TraceId)
+	assert.Equal(t, root.SpanId, child.ParentSpanId)
+}
+
+type traceContextRoutes struct {
+	endpoint *services.HttpEndpoint
+	spans    chan *services.SpanContext
+}
+
+func (c *traceContextRoutes) Register() {
+	c.endpoint.RegisterRoute("get", "/trace", nil, func(res http.ResponseWriter, req *http.Request) {
+		c.spans <- services.SpanFromRequest(req)
+		res.WriteHeader(204)
+	})
+}
+
+func TestHttpEndpointTraceContext(t *testing.T) {
+	endpoint := services.NewHttpEndpoint()
+	endpoint.Configure(cconf.NewConfigParamsFromTuples(
+		"connection.protocol", "http",
+		"connection.host", "localhost",
+		"connection.port", 0,
+	))
+	routes := &traceContextRoutes{endpoint: endpoint, spans: make(chan *services.SpanContext, 1)}
+	endpoint.Register(routes)
+	err := endpoint.Open("")
+	assert.Nil(t, err)
+	defer endpoint.Close("")
+	url := "http://" + endpoint.Addr() + "/trace"
+
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	req.Header.Add("tracestate", "rojo=00f067aa0ba902b7")
+	req.Header.Add("tracestate", "congo=t61rcWkgMzE")
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	span := <-routes.spans
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceId)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanId)
+	assert.False(t, span.IsSampled())
+	assert.Equal(t, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", span.TraceState)
+
+	// Invalid traceparent starts a new trace
+	req, _ = http.NewRequest("GET", url, nil)
+	req.Header.Set("traceparent", "invalid")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	span = <-routes.spans
+	assert.Len(t, span.TraceId, 32)
+	assert.Equal(t, "", span.ParentSpanId)
+	assert.True(t, span.IsSampled())
+}
+
+func TestInstrumentTimingSpan(t *testing.T) {
+	logger := newAccessLogCapture()
+	logger.SetLevel(clog.Trace)
+	span := services.NewSpanContext()
+
+	timing := services.NewInstrumentTiming("123", "dummy.exec", "exec", logger, nil, nil, nil).WithSpan(span)
+	timing.EndTiming(nil)
+
+	messages := logger.wait(1)
+	assert.Len(t, messages, 1)
+	assert.Contains(t, messages[0], span.TraceId)
+	assert.Contains(t, messages[0], span.SpanId)
+}